@@ -0,0 +1,147 @@
+package otelconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/sethvargo/go-envconfig"
+)
+
+// ConfigError is returned when an environment variable holds a value that can't be used,
+// such as OTEL_METRICS_ENABLED=ture. Endpoints, certificate files and compression are only
+// checked when the exporters are set up, which fails with a ConfigError naming the
+// variable the setting comes from, even if it was set with an option.
+type ConfigError struct {
+	// Variable is the name of the environment variable.
+	Variable string
+	// Value is the value that was rejected, with the values of sensitive headers masked.
+	Value string
+	// Err describes why the value was rejected.
+	Err error
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("invalid configuration: %s=%q: %v", e.Variable, e.Value, e.Err)
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// WithConfigErrorHandler decides what happens when an environment variable is invalid.
+// By default the *ConfigError is returned, and setting up OpenTelemetry fails. When the
+// handler returns nil instead, the variable is ignored, and the setting keeps the value
+// given by the options or the config file, or its default. Any other error is returned in
+// place of the *ConfigError.
+func WithConfigErrorHandler(handler func(err *ConfigError) error) Option {
+	return func(c *Config) {
+		c.configErrorHandler = handler
+	}
+}
+
+// WithConfigErrorsIgnored ignores every invalid environment variable, passing the
+// *ConfigError to the error handler instead of failing.
+func WithConfigErrorsIgnored() Option {
+	return func(c *Config) {
+		c.configErrorHandler = func(err *ConfigError) error {
+			c.errorHandler.Handle(err)
+			return nil
+		}
+	}
+}
+
+// otlpVariable returns the name of the OTEL_EXPORTER_OTLP_ variable for a setting of a
+// signal, such as TRACES, or of the generic variable if signal is empty.
+func otlpVariable(signal, setting string) string {
+	if signal == "" {
+		return "OTEL_EXPORTER_OTLP_" + setting
+	}
+	return "OTEL_EXPORTER_OTLP_" + signal + "_" + setting
+}
+
+// setting is the value of a setting and the environment variable it can be set by.
+type setting struct {
+	variable string
+	value    string
+}
+
+// signalSetting returns the OTEL_EXPORTER_OTLP_ setting of a signal, falling back to the
+// generic one if the signal doesn't set its own.
+func signalSetting(signal, name, value, generic string) setting {
+	if value != "" {
+		return setting{variable: otlpVariable(signal, name), value: value}
+	}
+	return setting{variable: otlpVariable("", name), value: generic}
+}
+
+// invalid returns a *ConfigError for a value of the setting that can't be used.
+func (s setting) invalid(err error) *ConfigError {
+	return &ConfigError{Variable: s.variable, Value: s.value, Err: err}
+}
+
+// handleConfigError returns the error unless the config error handler chooses to ignore it.
+func (c *Config) handleConfigError(err *ConfigError) error {
+	if c.configErrorHandler == nil {
+		return err
+	}
+	return c.configErrorHandler(err)
+}
+
+// processEnvironment applies the environment variables to the configuration. Each variable
+// is decoded on its own, so that one that fails is known for sure; it is passed to the
+// config error handler, and ignored when the handler lets it through.
+func processEnvironment(c *Config) error {
+	v := reflect.ValueOf(c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		tag, ok := field.Tag.Lookup("env")
+		if !ok {
+			continue
+		}
+		variable, _, _ := strings.Cut(tag, ",")
+
+		value, err := processVariable(field, v.Field(i), envconfig.OsLookuper())
+		if err == nil {
+			v.Field(i).Set(value)
+			continue
+		}
+		raw, _ := envconfig.OsLookuper().Lookup(variable)
+		configErr := &ConfigError{Variable: variable, Value: raw, Err: err}
+		if inner := errors.Unwrap(err); inner != nil {
+			configErr.Err = inner
+		}
+		if strings.HasSuffix(variable, "_HEADERS") {
+			// the value and the error quote the headers, which can hold credentials
+			configErr.Value = c.redactHeaderList(raw)
+			configErr.Err = envconfig.ErrInvalidMapItem
+		}
+		if err := c.handleConfigError(configErr); err != nil {
+			return err
+		}
+		// the variable is ignored, but its default still applies to an unset setting
+		if value, err = processVariable(field, v.Field(i), envconfig.MapLookuper(nil)); err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
+		v.Field(i).Set(value)
+	}
+	return nil
+}
+
+// processVariable decodes the environment variable of a single field of the configuration,
+// whose current value is current, returning its new value.
+func processVariable(field reflect.StructField, current reflect.Value, lookuper envconfig.Lookuper) (reflect.Value, error) {
+	target := reflect.New(reflect.StructOf([]reflect.StructField{{
+		Name: field.Name,
+		Type: field.Type,
+		Tag:  field.Tag,
+	}}))
+	target.Elem().Field(0).Set(current)
+	err := envconfig.ProcessWith(context.Background(), &envconfig.Config{
+		Target:   target.Interface(),
+		Lookuper: lookuper,
+	})
+	return target.Elem().Field(0), err
+}
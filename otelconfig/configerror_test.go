@@ -0,0 +1,121 @@
+package otelconfig
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingErrorHandler struct {
+	errs []error
+}
+
+func (h *recordingErrorHandler) Handle(err error) {
+	h.errs = append(h.errs, err)
+}
+
+func TestConfigErrorIsReturned(t *testing.T) {
+	t.Setenv("OTEL_METRICS_ENABLED", "ture")
+
+	_, err := newConfig(WithLogger(&testLogger{}))
+	var configErr *ConfigError
+	require.ErrorAs(t, err, &configErr)
+	assert.Equal(t, "OTEL_METRICS_ENABLED", configErr.Variable)
+	assert.Equal(t, "ture", configErr.Value)
+	assert.ErrorContains(t, err, `invalid configuration: OTEL_METRICS_ENABLED="ture"`)
+}
+
+func TestConfigErrorsIgnored(t *testing.T) {
+	t.Setenv("OTEL_METRICS_ENABLED", "ture")
+	t.Setenv("OTEL_BSP_MAX_QUEUE_SIZE", "lots")
+	t.Setenv("OTEL_TRACES_SAMPLER", "sometimes")
+	t.Setenv("OTEL_SERVICE_NAME", "env-service")
+
+	handler := &recordingErrorHandler{}
+	c, err := newConfig(
+		WithLogger(&testLogger{}),
+		WithErrorHandler(handler),
+		WithBatchSpanProcessorMaxQueueSize(4096),
+		WithConfigErrorsIgnored(),
+	)
+	require.NoError(t, err)
+
+	// the invalid variables fall back to the options and defaults, the others still apply
+	assert.True(t, *c.MetricsEnabled)
+	assert.Equal(t, 4096, c.BSPMaxQueueSize)
+	assert.Equal(t, "AlwaysOnSampler", c.Sampler.Description())
+	assert.Equal(t, "env-service", c.ServiceName)
+
+	var variables []string
+	for _, err := range handler.errs {
+		var configErr *ConfigError
+		require.ErrorAs(t, err, &configErr)
+		variables = append(variables, configErr.Variable)
+	}
+	assert.ElementsMatch(t, []string{
+		"OTEL_METRICS_ENABLED",
+		"OTEL_BSP_MAX_QUEUE_SIZE",
+		"OTEL_TRACES_SAMPLER",
+	}, variables)
+}
+
+func TestConfigErrorHandler(t *testing.T) {
+	t.Setenv("OTEL_BSP_SCHEDULE_DELAY", "1s")
+	t.Setenv("OTEL_SERVICE_NAME", "env-service")
+
+	errFatal := errors.New("fatal")
+	handler := func(err *ConfigError) error {
+		if err.Variable == "OTEL_BSP_SCHEDULE_DELAY" {
+			return nil
+		}
+		return errFatal
+	}
+
+	c, err := newConfig(
+		WithLogger(&testLogger{}),
+		WithBatchSpanProcessorScheduleDelay(time.Second),
+		WithConfigErrorHandler(handler),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 1000, c.BSPScheduleDelay)
+
+	t.Setenv("OTEL_TRACES_SAMPLER_ARG", "half")
+	t.Setenv("OTEL_TRACES_SAMPLER", "traceidratio")
+	_, err = newConfig(WithLogger(&testLogger{}), WithConfigErrorHandler(handler))
+	assert.ErrorIs(t, err, errFatal)
+}
+
+func TestConfigErrorMasksHeaders(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "x-honeycomb-team=abc123,x-tenant=otel,authorization:Bearer xyz")
+
+	handler := &recordingErrorHandler{}
+	_, err := newConfig(
+		WithLogger(&testLogger{}),
+		WithErrorHandler(handler),
+		WithConfigErrorsIgnored(),
+	)
+	require.NoError(t, err)
+	require.Len(t, handler.errs, 1)
+
+	var configErr *ConfigError
+	require.ErrorAs(t, handler.errs[0], &configErr)
+	assert.Equal(t, "OTEL_EXPORTER_OTLP_HEADERS", configErr.Variable)
+	assert.Equal(t, "x-honeycomb-team=****,x-tenant=otel,****", configErr.Value)
+	assert.NotContains(t, configErr.Error(), "abc123")
+	assert.NotContains(t, configErr.Error(), "xyz")
+}
+
+func TestConfigErrorNamesTheFailingVariable(t *testing.T) {
+	// a valid variable looked up after the invalid one doesn't take the blame
+	t.Setenv("OTEL_EXPORTER_OTLP_TIMEOUT", "soon")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_TIMEOUT", "1000")
+
+	_, err := newConfig(WithLogger(&testLogger{}))
+	var configErr *ConfigError
+	require.ErrorAs(t, err, &configErr)
+	assert.Equal(t, "OTEL_EXPORTER_OTLP_TIMEOUT", configErr.Variable)
+	assert.Equal(t, "soon", configErr.Value)
+}
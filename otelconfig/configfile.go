@@ -0,0 +1,713 @@
+package otelconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// fileConfig is the part of the OpenTelemetry declarative configuration schema that can be
+// expressed as a Config. Fields outside of it are rejected rather than silently ignored.
+type fileConfig struct {
+	FileFormat     string              `yaml:"file_format"`
+	Disabled       bool                `yaml:"disabled"`
+	Resource       *fileResource       `yaml:"resource"`
+	Propagator     *filePropagator     `yaml:"propagator"`
+	TracerProvider *fileTracerProvider `yaml:"tracer_provider"`
+	MeterProvider  *fileMeterProvider  `yaml:"meter_provider"`
+	LoggerProvider *fileLoggerProvider `yaml:"logger_provider"`
+}
+
+type fileResource struct {
+	Attributes     []fileAttribute `yaml:"attributes"`
+	AttributesList string          `yaml:"attributes_list"`
+}
+
+type fileAttribute struct {
+	Name  string      `yaml:"name"`
+	Value interface{} `yaml:"value"`
+	Type  string      `yaml:"type"`
+}
+
+type filePropagator struct {
+	Composite     []string `yaml:"composite"`
+	CompositeList string   `yaml:"composite_list"`
+}
+
+type fileTracerProvider struct {
+	Processors []fileProcessor `yaml:"processors"`
+	Sampler    fileOneOf       `yaml:"sampler"`
+}
+
+type fileMeterProvider struct {
+	Readers []fileReader `yaml:"readers"`
+	Views   []fileView   `yaml:"views"`
+}
+
+type fileLoggerProvider struct {
+	Processors []fileProcessor `yaml:"processors"`
+}
+
+type fileProcessor struct {
+	Batch  *fileBatchProcessor `yaml:"batch"`
+	Simple *struct {
+		Exporter fileOneOf `yaml:"exporter"`
+	} `yaml:"simple"`
+}
+
+type fileBatchProcessor struct {
+	ScheduleDelay      int       `yaml:"schedule_delay"`
+	ExportTimeout      int       `yaml:"export_timeout"`
+	MaxQueueSize       int       `yaml:"max_queue_size"`
+	MaxExportBatchSize int       `yaml:"max_export_batch_size"`
+	Exporter           fileOneOf `yaml:"exporter"`
+}
+
+type fileReader struct {
+	Periodic *struct {
+		Interval int       `yaml:"interval"`
+		Exporter fileOneOf `yaml:"exporter"`
+	} `yaml:"periodic"`
+	Pull *struct {
+		Exporter fileOneOf `yaml:"exporter"`
+	} `yaml:"pull"`
+}
+
+type fileOTLPExporter struct {
+	Protocol          Protocol        `yaml:"protocol"`
+	Endpoint          string          `yaml:"endpoint"`
+	Insecure          *bool           `yaml:"insecure"`
+	Certificate       string          `yaml:"certificate"`
+	ClientCertificate string          `yaml:"client_certificate"`
+	ClientKey         string          `yaml:"client_key"`
+	Headers           []fileNameValue `yaml:"headers"`
+	HeadersList       string          `yaml:"headers_list"`
+	Compression       string          `yaml:"compression"`
+	Timeout           int             `yaml:"timeout"`
+}
+
+type fileNameValue struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+type fileView struct {
+	Selector struct {
+		InstrumentName string `yaml:"instrument_name"`
+		InstrumentType string `yaml:"instrument_type"`
+		Unit           string `yaml:"unit"`
+		MeterName      string `yaml:"meter_name"`
+		MeterVersion   string `yaml:"meter_version"`
+		MeterSchemaURL string `yaml:"meter_schema_url"`
+	} `yaml:"selector"`
+	Stream struct {
+		Name          string    `yaml:"name"`
+		Description   string    `yaml:"description"`
+		Aggregation   fileOneOf `yaml:"aggregation"`
+		AttributeKeys *struct {
+			Included []string `yaml:"included"`
+			Excluded []string `yaml:"excluded"`
+		} `yaml:"attribute_keys"`
+	} `yaml:"stream"`
+}
+
+// fileOneOf holds an object of which exactly one kind may be set, such as a sampler or an
+// exporter. Many kinds take no settings and are written with an empty value, which is why
+// they are kept as nodes until we know which kind was chosen.
+type fileOneOf map[string]yaml.Node
+
+// kind returns the kind that was chosen and its settings.
+func (o fileOneOf) kind(what string) (string, *yaml.Node, error) {
+	if len(o) != 1 {
+		kinds := make([]string, 0, len(o))
+		for k := range o {
+			kinds = append(kinds, k)
+		}
+		sort.Strings(kinds)
+		return "", nil, fmt.Errorf("%s must have exactly one kind, got %q", what, kinds)
+	}
+	for k, v := range o {
+		return k, &v, nil
+	}
+	return "", nil, nil
+}
+
+// envReference matches the environment variable references that are substituted in a config
+// file: ${NAME}, ${env:NAME} and ${NAME:-default}. $$ escapes a dollar sign.
+var envReference = regexp.MustCompile(`\$\$|\$\{(?:env:)?([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// substituteEnv replaces the environment variable references in the scalar values of a
+// parsed config file. As it works on values rather than on the text of the file, a variable
+// can't add or remove structure, and references in keys and comments are left alone.
+func substituteEnv(node *yaml.Node) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		value := envReference.ReplaceAllStringFunc(node.Value, func(ref string) string {
+			if ref == "$$" {
+				return "$"
+			}
+			m := envReference.FindStringSubmatch(ref)
+			if value, ok := os.LookupEnv(m[1]); ok {
+				return value
+			}
+			return m[2]
+		})
+		if value == node.Value {
+			return
+		}
+		node.Value = value
+		// the type of a plain value is that of what it was replaced with, as in
+		// ratio: ${RATIO}
+		if node.Style == 0 {
+			node.Tag = ""
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			substituteEnv(node.Content[i])
+		}
+	default:
+		for _, child := range node.Content {
+			substituteEnv(child)
+		}
+	}
+}
+
+// decodeYAML decodes data into v, failing on fields that v doesn't have.
+func decodeYAML(data []byte, v interface{}) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	err := dec.Decode(v)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+// decodeNode decodes the settings of a fileOneOf kind. An empty node leaves v unchanged.
+func decodeNode(node *yaml.Node, v interface{}) error {
+	if node.Kind == 0 || node.Tag == "!!null" {
+		return nil
+	}
+	// yaml.Node.Decode doesn't check for unknown fields, so go round through the encoder
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return err
+	}
+	return decodeYAML(data, v)
+}
+
+// loadConfigFile reads a declarative configuration file, substituting environment variables,
+// and applies it to c.
+func loadConfigFile(c *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: failed to read config file: %w", err)
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return fmt.Errorf("invalid configuration: failed to parse config file %s: %w", path, err)
+	}
+	substituteEnv(&root)
+	var fc fileConfig
+	if err := decodeNode(&root, &fc); err != nil {
+		return fmt.Errorf("invalid configuration: failed to parse config file %s: %w", path, err)
+	}
+	if err := fc.apply(c); err != nil {
+		return fmt.Errorf("invalid configuration: config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// apply fills in c from the config file. As in the declarative configuration schema, a
+// signal whose provider is missing from the file is disabled.
+func (fc *fileConfig) apply(c *Config) error {
+	if fc.FileFormat == "" {
+		return errors.New("file_format is required")
+	}
+	disabled := false
+	if fc.Disabled {
+		c.TracesEnabled = &disabled
+		c.MetricsEnabled = &disabled
+		c.LogsEnabled = &disabled
+		return nil
+	}
+
+	if fc.Resource != nil {
+		if err := fc.Resource.apply(c); err != nil {
+			return err
+		}
+	}
+	if fc.Propagator != nil {
+		c.Propagators = append(fc.Propagator.Composite, splitList(fc.Propagator.CompositeList)...)
+	}
+
+	if fc.TracerProvider == nil {
+		c.TracesEnabled = &disabled
+	} else if err := fc.TracerProvider.apply(c); err != nil {
+		return fmt.Errorf("tracer_provider: %w", err)
+	}
+	if fc.MeterProvider == nil {
+		c.MetricsEnabled = &disabled
+	} else if err := fc.MeterProvider.apply(c); err != nil {
+		return fmt.Errorf("meter_provider: %w", err)
+	}
+	if fc.LoggerProvider == nil {
+		c.LogsEnabled = &disabled
+	} else {
+		// logs are off by default, so a logger_provider turns them on
+		enabled := true
+		c.LogsEnabled = &enabled
+		if err := fc.LoggerProvider.apply(c); err != nil {
+			return fmt.Errorf("logger_provider: %w", err)
+		}
+	}
+	return nil
+}
+
+// apply adds the resource attributes to c. Like OTEL_RESOURCE_ATTRIBUTES, all values are
+// recorded as strings.
+func (r *fileResource) apply(c *Config) error {
+	attrs, err := parseKeyValueList(r.AttributesList)
+	if err != nil {
+		return fmt.Errorf("resource: %w", err)
+	}
+	for _, attr := range r.Attributes {
+		if attr.Name == "" {
+			return errors.New("resource: attribute name is required")
+		}
+		attrs[attr.Name] = fmt.Sprint(attr.Value)
+	}
+	for k, v := range attrs {
+		switch k {
+		case "service.name":
+			c.ServiceName = v
+		case "service.version":
+			c.ServiceVersion = v
+		default:
+			c.ResourceAttributes[k] = v
+		}
+	}
+	return nil
+}
+
+func (tp *fileTracerProvider) apply(c *Config) error {
+	signal := otlpSignal{
+		protocol:          &c.TracesExporterProtocol,
+		endpoint:          &c.TracesExporterEndpoint,
+		insecure:          &c.TracesExporterEndpointInsecure,
+		certificate:       &c.TracesCertificate,
+		clientCertificate: &c.TracesClientCertificate,
+		clientKey:         &c.TracesClientKey,
+		headers:           c.TracesHeaders,
+		compression:       &c.TracesExporterCompression,
+		timeout:           &c.TracesExporterTimeout,
+	}
+	var exporters []string
+	for _, p := range tp.Processors {
+		if p.Simple != nil || p.Batch == nil {
+			return errors.New("only batch span processors are supported")
+		}
+		c.BSPScheduleDelay = firstNonZero(p.Batch.ScheduleDelay, c.BSPScheduleDelay)
+		c.BSPExportTimeout = firstNonZero(p.Batch.ExportTimeout, c.BSPExportTimeout)
+		c.BSPMaxQueueSize = firstNonZero(p.Batch.MaxQueueSize, c.BSPMaxQueueSize)
+		c.BSPMaxExportBatchSize = firstNonZero(p.Batch.MaxExportBatchSize, c.BSPMaxExportBatchSize)
+		name, err := signal.applyExporter(p.Batch.Exporter, exporters)
+		if err != nil {
+			return err
+		}
+		exporters = append(exporters, name)
+	}
+	if len(exporters) == 0 {
+		exporters = []string{ExporterNone}
+	}
+	c.TracesExporters = exporters
+
+	if tp.Sampler != nil {
+		sampler, err := newFileSampler(tp.Sampler)
+		if err != nil {
+			return err
+		}
+		c.Sampler = sampler
+	}
+	return nil
+}
+
+func (mp *fileMeterProvider) apply(c *Config) error {
+	signal := otlpSignal{
+		protocol:          &c.MetricsExporterProtocol,
+		endpoint:          &c.MetricsExporterEndpoint,
+		insecure:          &c.MetricsExporterEndpointInsecure,
+		certificate:       &c.MetricsCertificate,
+		clientCertificate: &c.MetricsClientCertificate,
+		clientKey:         &c.MetricsClientKey,
+		headers:           c.MetricsHeaders,
+		compression:       &c.MetricsExporterCompression,
+		timeout:           &c.MetricsExporterTimeout,
+	}
+	var exporters []string
+	var interval time.Duration
+	for _, r := range mp.Readers {
+		switch {
+		case r.Periodic != nil && r.Pull == nil:
+			readerInterval := time.Duration(r.Periodic.Interval) * time.Millisecond
+			if interval != 0 && readerInterval != interval {
+				return errors.New("all periodic readers must use the same interval")
+			}
+			interval = readerInterval
+			name, err := signal.applyExporter(r.Periodic.Exporter, exporters)
+			if err != nil {
+				return err
+			}
+			exporters = append(exporters, name)
+		case r.Pull != nil && r.Periodic == nil:
+			name, body, err := r.Pull.Exporter.kind("exporter")
+			if err != nil {
+				return err
+			}
+			if name != ExporterPrometheus {
+				return fmt.Errorf("unsupported pull exporter %q", name)
+			}
+			var prom struct {
+				Host string `yaml:"host"`
+				Port int    `yaml:"port"`
+			}
+			if err := decodeNode(body, &prom); err != nil {
+				return err
+			}
+			c.PrometheusHost = firstNonEmpty(prom.Host, c.PrometheusHost)
+			c.PrometheusPort = firstNonZero(prom.Port, c.PrometheusPort)
+			exporters = append(exporters, ExporterPrometheus)
+		default:
+			return errors.New("readers must be either periodic or pull")
+		}
+	}
+	if interval != 0 {
+		c.MetricsReportingPeriod = interval.String()
+	}
+	if len(exporters) == 0 {
+		exporters = []string{ExporterNone}
+	}
+	c.MetricsExporters = exporters
+
+	for _, v := range mp.Views {
+		view, err := v.newView()
+		if err != nil {
+			return fmt.Errorf("views: %w", err)
+		}
+		c.MetricViews = append(c.MetricViews, view)
+	}
+	return nil
+}
+
+func (lp *fileLoggerProvider) apply(c *Config) error {
+	signal := otlpSignal{
+		protocol:          &c.LogsExporterProtocol,
+		endpoint:          &c.LogsExporterEndpoint,
+		insecure:          &c.LogsExporterEndpointInsecure,
+		certificate:       &c.LogsCertificate,
+		clientCertificate: &c.LogsClientCertificate,
+		clientKey:         &c.LogsClientKey,
+		headers:           c.LogsHeaders,
+		compression:       &c.LogsExporterCompression,
+		timeout:           &c.LogsExporterTimeout,
+	}
+	var exporters []string
+	for _, p := range lp.Processors {
+		if p.Simple != nil || p.Batch == nil {
+			return errors.New("only batch log record processors are supported")
+		}
+		if p.Batch.ScheduleDelay != 0 || p.Batch.ExportTimeout != 0 ||
+			p.Batch.MaxQueueSize != 0 || p.Batch.MaxExportBatchSize != 0 {
+			return errors.New("batch log record processor settings are not supported")
+		}
+		name, err := signal.applyExporter(p.Batch.Exporter, exporters)
+		if err != nil {
+			return err
+		}
+		exporters = append(exporters, name)
+	}
+	if len(exporters) == 0 {
+		exporters = []string{ExporterNone}
+	}
+	c.LogsExporters = exporters
+	return nil
+}
+
+// otlpSignal points at the Config fields for the OTLP exporter of one signal.
+type otlpSignal struct {
+	protocol          *Protocol
+	endpoint          *string
+	insecure          *bool
+	certificate       *string
+	clientCertificate *string
+	clientKey         *string
+	headers           map[string]string
+	compression       *string
+	timeout           *int
+}
+
+// applyExporter applies the settings of an exporter and returns its name. Each signal has
+// a single OTLP exporter, so it can only be configured once.
+func (s otlpSignal) applyExporter(exporter fileOneOf, exporters []string) (string, error) {
+	name, body, err := exporter.kind("exporter")
+	if err != nil {
+		return "", err
+	}
+	switch name {
+	case ExporterOTLP:
+		for _, e := range exporters {
+			if e == ExporterOTLP {
+				return "", errors.New("only one otlp exporter is supported")
+			}
+		}
+		var otlp fileOTLPExporter
+		if err := decodeNode(body, &otlp); err != nil {
+			return "", err
+		}
+		return name, s.apply(otlp)
+	case ExporterConsole:
+		return name, nil
+	default:
+		return "", fmt.Errorf("unsupported exporter %q", name)
+	}
+}
+
+func (s otlpSignal) apply(e fileOTLPExporter) error {
+	headers, err := parseKeyValueList(e.HeadersList)
+	if err != nil {
+		return fmt.Errorf("headers_list: %w", err)
+	}
+	for _, h := range e.Headers {
+		headers[h.Name] = h.Value
+	}
+	for k, v := range headers {
+		s.headers[k] = v
+	}
+	if e.Protocol != "" {
+		*s.protocol = e.Protocol
+	}
+	if e.Insecure != nil {
+		*s.insecure = *e.Insecure
+	}
+	*s.endpoint = firstNonEmpty(e.Endpoint, *s.endpoint)
+	*s.certificate = firstNonEmpty(e.Certificate, *s.certificate)
+	*s.clientCertificate = firstNonEmpty(e.ClientCertificate, *s.clientCertificate)
+	*s.clientKey = firstNonEmpty(e.ClientKey, *s.clientKey)
+	*s.compression = firstNonEmpty(e.Compression, *s.compression)
+	*s.timeout = firstNonZero(e.Timeout, *s.timeout)
+	return nil
+}
+
+// newFileSampler builds a sampler from its declarative configuration.
+func newFileSampler(s fileOneOf) (trace.Sampler, error) {
+	name, body, err := s.kind("sampler")
+	if err != nil {
+		return nil, err
+	}
+	switch name {
+	case "always_on":
+		return trace.AlwaysSample(), nil
+	case "always_off":
+		return trace.NeverSample(), nil
+	case "trace_id_ratio_based":
+		cfg := struct {
+			Ratio float64 `yaml:"ratio"`
+		}{Ratio: 1}
+		if err := decodeNode(body, &cfg); err != nil {
+			return nil, err
+		}
+		if cfg.Ratio < 0 || cfg.Ratio > 1 {
+			return nil, fmt.Errorf("sampler ratio %v must be between 0 and 1", cfg.Ratio)
+		}
+		return trace.TraceIDRatioBased(cfg.Ratio), nil
+	case "parent_based":
+		var cfg struct {
+			Root                   fileOneOf `yaml:"root"`
+			RemoteParentSampled    fileOneOf `yaml:"remote_parent_sampled"`
+			RemoteParentNotSampled fileOneOf `yaml:"remote_parent_not_sampled"`
+			LocalParentSampled     fileOneOf `yaml:"local_parent_sampled"`
+			LocalParentNotSampled  fileOneOf `yaml:"local_parent_not_sampled"`
+		}
+		if err := decodeNode(body, &cfg); err != nil {
+			return nil, err
+		}
+		root := trace.AlwaysSample()
+		if cfg.Root != nil {
+			if root, err = newFileSampler(cfg.Root); err != nil {
+				return nil, err
+			}
+		}
+		var opts []trace.ParentBasedSamplerOption
+		for _, delegate := range []struct {
+			sampler fileOneOf
+			option  func(trace.Sampler) trace.ParentBasedSamplerOption
+		}{
+			{cfg.RemoteParentSampled, trace.WithRemoteParentSampled},
+			{cfg.RemoteParentNotSampled, trace.WithRemoteParentNotSampled},
+			{cfg.LocalParentSampled, trace.WithLocalParentSampled},
+			{cfg.LocalParentNotSampled, trace.WithLocalParentNotSampled},
+		} {
+			if delegate.sampler == nil {
+				continue
+			}
+			sampler, err := newFileSampler(delegate.sampler)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, delegate.option(sampler))
+		}
+		return trace.ParentBased(root, opts...), nil
+	default:
+		return nil, fmt.Errorf("unsupported sampler %q", name)
+	}
+}
+
+var instrumentKinds = map[string]metric.InstrumentKind{
+	"counter":                    metric.InstrumentKindCounter,
+	"up_down_counter":            metric.InstrumentKindUpDownCounter,
+	"histogram":                  metric.InstrumentKindHistogram,
+	"gauge":                      metric.InstrumentKindGauge,
+	"observable_counter":         metric.InstrumentKindObservableCounter,
+	"observable_up_down_counter": metric.InstrumentKindObservableUpDownCounter,
+	"observable_gauge":           metric.InstrumentKindObservableGauge,
+}
+
+// defaultHistogramBoundaries are the explicit bucket boundaries used when a view doesn't set any.
+var defaultHistogramBoundaries = []float64{0, 5, 10, 25, 50, 75, 100, 250, 500, 750, 1000, 2500, 5000, 7500, 10000}
+
+func (v fileView) newView() (metric.View, error) {
+	sel := v.Selector
+	criteria := metric.Instrument{
+		Name: sel.InstrumentName,
+		Unit: sel.Unit,
+		Scope: instrumentation.Scope{
+			Name:      sel.MeterName,
+			Version:   sel.MeterVersion,
+			SchemaURL: sel.MeterSchemaURL,
+		},
+	}
+	if sel.InstrumentType != "" {
+		kind, ok := instrumentKinds[sel.InstrumentType]
+		if !ok {
+			return nil, fmt.Errorf("unsupported instrument_type %q", sel.InstrumentType)
+		}
+		criteria.Kind = kind
+	}
+	if criteria.Name == "" && criteria.Kind == 0 && criteria.Unit == "" && criteria.Scope == (instrumentation.Scope{}) {
+		return nil, errors.New("selector must not be empty")
+	}
+
+	mask := metric.Stream{
+		Name:        v.Stream.Name,
+		Description: v.Stream.Description,
+	}
+	if v.Stream.Aggregation != nil {
+		aggregation, err := newFileAggregation(v.Stream.Aggregation)
+		if err != nil {
+			return nil, err
+		}
+		mask.Aggregation = aggregation
+	}
+	if keys := v.Stream.AttributeKeys; keys != nil {
+		included := make(map[attribute.Key]bool, len(keys.Included))
+		for _, k := range keys.Included {
+			included[attribute.Key(k)] = true
+		}
+		excluded := make(map[attribute.Key]bool, len(keys.Excluded))
+		for _, k := range keys.Excluded {
+			excluded[attribute.Key(k)] = true
+		}
+		mask.AttributeFilter = func(kv attribute.KeyValue) bool {
+			return (keys.Included == nil || included[kv.Key]) && !excluded[kv.Key]
+		}
+	}
+	return metric.NewView(criteria, mask), nil
+}
+
+func newFileAggregation(a fileOneOf) (metric.Aggregation, error) {
+	name, body, err := a.kind("aggregation")
+	if err != nil {
+		return nil, err
+	}
+	switch name {
+	case "default":
+		return metric.AggregationDefault{}, nil
+	case "drop":
+		return metric.AggregationDrop{}, nil
+	case "sum":
+		return metric.AggregationSum{}, nil
+	case "last_value":
+		return metric.AggregationLastValue{}, nil
+	case "explicit_bucket_histogram":
+		cfg := struct {
+			Boundaries   []float64 `yaml:"boundaries"`
+			RecordMinMax bool      `yaml:"record_min_max"`
+		}{Boundaries: defaultHistogramBoundaries, RecordMinMax: true}
+		if err := decodeNode(body, &cfg); err != nil {
+			return nil, err
+		}
+		return metric.AggregationExplicitBucketHistogram{
+			Boundaries: cfg.Boundaries,
+			NoMinMax:   !cfg.RecordMinMax,
+		}, nil
+	case "base2_exponential_bucket_histogram":
+		cfg := struct {
+			MaxScale     int32 `yaml:"max_scale"`
+			MaxSize      int32 `yaml:"max_size"`
+			RecordMinMax bool  `yaml:"record_min_max"`
+		}{MaxScale: 20, MaxSize: 160, RecordMinMax: true}
+		if err := decodeNode(body, &cfg); err != nil {
+			return nil, err
+		}
+		return metric.AggregationBase2ExponentialHistogram{
+			MaxScale: cfg.MaxScale,
+			MaxSize:  cfg.MaxSize,
+			NoMinMax: !cfg.RecordMinMax,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported aggregation %q", name)
+	}
+}
+
+// parseKeyValueList parses a comma-separated list of key=value pairs with percent-encoded
+// values, in the format of OTEL_RESOURCE_ATTRIBUTES and OTEL_EXPORTER_OTLP_HEADERS.
+func parseKeyValueList(list string) (map[string]string, error) {
+	values := map[string]string{}
+	for _, pair := range splitList(list) {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("invalid key=value pair %q", pair)
+		}
+		v, err := url.PathUnescape(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %q: %w", k, err)
+		}
+		values[strings.TrimSpace(k)] = v
+	}
+	return values, nil
+}
+
+// splitList splits a comma-separated list, dropping empty entries.
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,266 @@
+package otelconfig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "otel.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+	return path
+}
+
+func TestConfigFile(t *testing.T) {
+	t.Setenv("API_KEY", "secret")
+	path := writeConfigFile(t, `
+file_format: "0.3"
+resource:
+  attributes:
+    - name: service.name
+      value: file-service
+    - name: deployment.environment
+      value: ${ENVIRONMENT:-staging}
+  attributes_list: team=platform
+propagator:
+  composite: [tracecontext, b3]
+tracer_provider:
+  processors:
+    - batch:
+        schedule_delay: 1000
+        max_queue_size: 4096
+        exporter:
+          otlp:
+            protocol: http/protobuf
+            endpoint: collector:4318
+            insecure: true
+            headers:
+              - name: api-key
+                value: ${env:API_KEY}
+            compression: none
+            timeout: 2000
+    - batch:
+        exporter:
+          console:
+  sampler:
+    parent_based:
+      root:
+        trace_id_ratio_based:
+          ratio: 0.25
+meter_provider:
+  readers:
+    - periodic:
+        interval: 5000
+        exporter:
+          otlp:
+            endpoint: collector:4317
+    - pull:
+        exporter:
+          prometheus:
+            port: 9999
+  views:
+    - selector:
+        instrument_name: http.server.duration
+      stream:
+        aggregation:
+          explicit_bucket_histogram:
+            boundaries: [10, 100, 1000]
+`)
+
+	c, err := newConfig(WithLogger(&testLogger{}), WithConfigFile(path))
+	require.NoError(t, err)
+
+	assert.Equal(t, "file-service", c.ServiceName)
+	assert.Equal(t, map[string]string{"deployment.environment": "staging", "team": "platform"}, c.ResourceAttributes)
+	assert.Equal(t, []string{"tracecontext", "b3"}, c.Propagators)
+
+	assert.Equal(t, Protocol("http/protobuf"), c.TracesExporterProtocol)
+	assert.Equal(t, "collector:4318", c.TracesExporterEndpoint)
+	assert.True(t, c.TracesExporterEndpointInsecure)
+	assert.Equal(t, map[string]string{"api-key": "secret"}, c.TracesHeaders)
+	assert.Equal(t, "none", c.TracesExporterCompression)
+	assert.Equal(t, 2000, c.TracesExporterTimeout)
+	assert.Equal(t, 1000, c.BSPScheduleDelay)
+	assert.Equal(t, 4096, c.BSPMaxQueueSize)
+	assert.Equal(t, []string{ExporterOTLP, ExporterConsole}, c.TracesExporters)
+	assert.Equal(t, "ParentBased{root:TraceIDRatioBased{0.25},remoteParentSampled:AlwaysOnSampler,"+
+		"remoteParentNotSampled:AlwaysOffSampler,localParentSampled:AlwaysOnSampler,"+
+		"localParentNotSampled:AlwaysOffSampler}", c.Sampler.Description())
+
+	assert.Equal(t, "collector:4317", c.MetricsExporterEndpoint)
+	assert.Equal(t, "5s", c.MetricsReportingPeriod)
+	assert.Equal(t, []string{ExporterOTLP, ExporterPrometheus}, c.MetricsExporters)
+	assert.Equal(t, 9999, c.PrometheusPort)
+	assert.Len(t, c.MetricViews, 1)
+
+	// there is no logger_provider, so logs are disabled
+	assert.False(t, *c.LogsEnabled)
+}
+
+func TestConfigFileSubstitutesValuesOnly(t *testing.T) {
+	t.Setenv("API_KEY", "secret\n            exporter: evil")
+	t.Setenv("TEAM", "platform: true # not a comment")
+	t.Setenv("RATIO", "0.5")
+	path := writeConfigFile(t, `
+file_format: "0.3"
+resource:
+  attributes:
+    # ${UNSET_IN_A_COMMENT:-} is left alone
+    - name: team
+      value: ${TEAM}
+tracer_provider:
+  processors:
+    - batch:
+        exporter:
+          otlp:
+            endpoint: collector:4318
+            headers:
+              - name: api-key
+                value: ${API_KEY}
+  sampler:
+    trace_id_ratio_based:
+      ratio: ${RATIO}
+`)
+
+	c, err := newConfig(WithLogger(&testLogger{}), WithConfigFile(path))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"api-key": "secret\n            exporter: evil"}, c.TracesHeaders)
+	assert.Equal(t, "platform: true # not a comment", c.ResourceAttributes["team"])
+	assert.Equal(t, "TraceIDRatioBased{0.5}", c.Sampler.Description())
+}
+
+func TestConfigFileFromEnvironment(t *testing.T) {
+	path := writeConfigFile(t, `
+file_format: "0.3"
+resource:
+  attributes:
+    - name: service.name
+      value: file-service
+    - name: service.version
+      value: "1.2.3"
+`)
+	t.Setenv("OTEL_CONFIG_FILE", path)
+	t.Setenv("OTEL_SERVICE_NAME", "env-service")
+
+	c, err := newConfig(WithLogger(&testLogger{}), WithServiceName("option-service"))
+	require.NoError(t, err)
+
+	assert.Equal(t, path, c.ConfigFile)
+	// the file overrides options, and environment variables override the file
+	assert.Equal(t, "env-service", c.ServiceName)
+	assert.Equal(t, "1.2.3", c.ServiceVersion)
+	assert.False(t, *c.TracesEnabled)
+	assert.False(t, *c.MetricsEnabled)
+}
+
+func TestConfigFileViews(t *testing.T) {
+	path := writeConfigFile(t, `
+file_format: "0.3"
+meter_provider:
+  readers:
+    - periodic:
+        exporter:
+          console:
+  views:
+    - selector:
+        instrument_name: requests
+        meter_name: otelconfig-tests
+      stream:
+        name: renamed_requests
+        attribute_keys:
+          excluded: [user]
+`)
+	var buf syncBuffer
+	sdk, err := NewSDK(WithLogger(&testLogger{}), WithConfigFile(path), WithConsoleWriter(&buf))
+	require.NoError(t, err)
+	defer sdk.Shutdown(context.Background())
+	require.Nil(t, sdk.TracerProvider)
+
+	counter, err := sdk.MeterProvider.Meter("otelconfig-tests").Int64Counter("requests")
+	require.NoError(t, err)
+	counter.Add(context.Background(), 1)
+	require.NoError(t, sdk.ForceFlush(context.Background()))
+
+	out := buf.String()
+	assert.Contains(t, out, `"Name": "renamed_requests"`)
+	assert.NotContains(t, out, `"Name": "requests"`)
+}
+
+func TestConfigFileErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		contents string
+		expected string
+	}{
+		{
+			name:     "missing file format",
+			contents: "tracer_provider: {}",
+			expected: "file_format is required",
+		},
+		{
+			name:     "unknown field",
+			contents: "file_format: \"0.3\"\ntracer_provider:\n  limits: {}",
+			expected: "field limits not found",
+		},
+		{
+			name: "simple processor",
+			contents: `
+file_format: "0.3"
+tracer_provider:
+  processors:
+    - simple:
+        exporter:
+          console:`,
+			expected: "tracer_provider: only batch span processors are supported",
+		},
+		{
+			name: "two samplers",
+			contents: `
+file_format: "0.3"
+tracer_provider:
+  sampler:
+    always_on:
+    always_off:`,
+			expected: `sampler must have exactly one kind, got ["always_off" "always_on"]`,
+		},
+		{
+			name: "unsupported exporter",
+			contents: `
+file_format: "0.3"
+logger_provider:
+  processors:
+    - batch:
+        exporter:
+          zipkin:`,
+			expected: `logger_provider: unsupported exporter "zipkin"`,
+		},
+		{
+			name: "unknown exporter setting",
+			contents: `
+file_format: "0.3"
+meter_provider:
+  readers:
+    - periodic:
+        exporter:
+          otlp:
+            temporality_preference: delta`,
+			expected: "field temporality_preference not found",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newConfig(WithLogger(&testLogger{}), WithConfigFile(writeConfigFile(t, tc.contents)))
+			require.Error(t, err)
+			assert.ErrorContains(t, err, "invalid configuration")
+			assert.ErrorContains(t, err, tc.expected)
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package otelconfig
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// The paths the HTTP exporters append to a generic endpoint, as set by the specification.
+const (
+	tracesURLPath  = "/v1/traces"
+	metricsURLPath = "/v1/metrics"
+	logsURLPath    = "/v1/logs"
+)
+
+// exporterEndpoint is where an OTLP exporter sends a signal.
+type exporterEndpoint struct {
+	// Host is the host and port, such as collector:4317 or [::1]:4318.
+	Host string
+	// Path is the URL path the HTTP exporters post to; it is empty for gRPC.
+	Path string
+	// Socket is the path of the Unix domain socket to connect to, if any.
+	Socket string
+	// Scheme is the scheme of the endpoint, or empty if it was given without one.
+	Scheme   string
+	Insecure bool
+}
+
+func (c *Config) getTracesEndpoint() (exporterEndpoint, error) {
+	return c.getSignalEndpoint("TRACES", c.TracesExporterEndpoint, c.TracesExporterEndpointInsecure,
+		&c.TracesExporterProtocol, tracesURLPath)
+}
+
+func (c *Config) getMetricsEndpoint() (exporterEndpoint, error) {
+	return c.getSignalEndpoint("METRICS", c.MetricsExporterEndpoint, c.MetricsExporterEndpointInsecure,
+		&c.MetricsExporterProtocol, metricsURLPath)
+}
+
+func (c *Config) getLogsEndpoint() (exporterEndpoint, error) {
+	return c.getSignalEndpoint("LOGS", c.LogsExporterEndpoint, c.LogsExporterEndpointInsecure,
+		&c.LogsExporterProtocol, logsURLPath)
+}
+
+// getSignalEndpoint resolves the endpoint of a signal, falling back to the generic endpoint
+// if the signal doesn't set its own. A signal without an endpoint is disabled, and gets an
+// empty Host. The protocol of the signal falls back to the generic one as well. An invalid
+// endpoint is reported as a *ConfigError naming the variable it is set by.
+func (c *Config) getSignalEndpoint(signal, endpoint string, insecure bool, protocol *Protocol, signalPath string) (exporterEndpoint, error) {
+	if *protocol == "" {
+		*protocol = c.ExporterProtocol
+	}
+
+	generic := false
+	if endpoint == "" {
+		if c.ExporterEndpoint == "" {
+			return exporterEndpoint{}, nil
+		}
+		endpoint, insecure, generic, signal = c.ExporterEndpoint, c.ExporterEndpointInsecure, true, ""
+	}
+
+	e, err := parseEndpoint(endpoint, *protocol)
+	if err != nil {
+		return exporterEndpoint{}, &ConfigError{
+			Variable: otlpVariable(signal, "ENDPOINT"),
+			Value:    redactEndpoint(endpoint),
+			Err:      err,
+		}
+	}
+	// the scheme decides whether the connection is secure, falling back to the insecure
+	// setting for an endpoint without one
+	switch e.Scheme {
+	case "http", "unix":
+		e.Insecure = true
+	case "https":
+		e.Insecure = false
+	default:
+		e.Insecure = insecure
+	}
+	if *protocol == ProtocolGRPC {
+		e.Path = ""
+	} else if generic {
+		// a generic endpoint is the base URL the signal's own path is appended to
+		e.Path = strings.TrimSuffix(e.Path, "/") + signalPath
+	} else if e.Socket != "" {
+		// a socket can't carry a path, so the signal's own path is used
+		e.Path = signalPath
+	} else if e.Path == "" {
+		// a signal endpoint is used as given, with the root path if it has none
+		e.Path = "/"
+	}
+	return e, nil
+}
+
+// parseEndpoint parses an endpoint, which is either a URL such as https://collector:4318/otlp,
+// a host with an optional port and path, such as collector, [::1]:4317 or collector/otlp, or
+// a Unix domain socket such as unix:///var/run/otel/otlp.sock. A missing port defaults to the
+// port of the scheme, or to the default port of the protocol.
+func parseEndpoint(endpoint string, protocol Protocol) (exporterEndpoint, error) {
+	invalid := func(reason string) (exporterEndpoint, error) {
+		return exporterEndpoint{}, fmt.Errorf("invalid endpoint: %s", reason)
+	}
+
+	raw := strings.TrimSpace(endpoint)
+	scheme := ""
+	if i := strings.Index(raw, "://"); i >= 0 {
+		scheme, raw = strings.ToLower(raw[:i]), raw[i+len("://"):]
+	} else if ip := net.ParseIP(raw); ip != nil && strings.Contains(raw, ":") {
+		// a bare IPv6 address, whose last group would otherwise be taken for the port
+		raw = "[" + raw + "]"
+	}
+	switch scheme {
+	case "", "http", "https":
+	case "unix":
+		if raw == "" {
+			return invalid("missing socket path")
+		}
+		// the host is only used for the Host header and the authority of requests
+		return exporterEndpoint{Host: "localhost", Socket: raw, Scheme: scheme}, nil
+	default:
+		return invalid(fmt.Sprintf("unsupported scheme %q, use http, https or unix", scheme))
+	}
+
+	u, err := url.Parse("//" + raw)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return invalid(err.Error())
+	}
+	switch {
+	case u.User != nil:
+		return invalid("credentials are not supported in the endpoint, set them as headers")
+	case u.RawQuery != "" || u.Fragment != "" || strings.HasSuffix(raw, "?") || strings.HasSuffix(raw, "#"):
+		return invalid("an endpoint can't have a query or a fragment")
+	case u.Hostname() == "":
+		return invalid("missing host")
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = defaultEndpointPort(scheme, protocol)
+	} else if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return invalid(fmt.Sprintf("port %s is out of range", port))
+	}
+	return exporterEndpoint{
+		Host:   net.JoinHostPort(u.Hostname(), port),
+		Path:   u.Path,
+		Scheme: scheme,
+	}, nil
+}
+
+// defaultEndpointPort returns the port used when an endpoint doesn't have one. gRPC uses
+// its default port unless https is given explicitly, while a URL for the HTTP exporters
+// uses the port of its scheme.
+func defaultEndpointPort(scheme string, protocol Protocol) string {
+	switch {
+	case scheme == "https":
+		return SSLDefaultPort
+	case protocol == ProtocolGRPC:
+		return GRPCDefaultPort
+	case scheme == "http":
+		return "80"
+	default:
+		return HTTPDefaultPort
+	}
+}
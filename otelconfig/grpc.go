@@ -0,0 +1,23 @@
+package otelconfig
+
+import (
+	"google.golang.org/grpc"
+)
+
+// WithGRPCDialOptions adds dial options to those of the gRPC exporters, such as keepalive
+// parameters, a service config with a load balancing policy, or interceptors. They are
+// applied after the exporters' own, so they take precedence.
+func WithGRPCDialOptions(opts ...grpc.DialOption) Option {
+	return func(c *Config) {
+		c.grpcDialOptions = append(c.grpcDialOptions, opts...)
+	}
+}
+
+// WithGRPCConn makes the gRPC exporters of every signal share conn instead of each dialing
+// the endpoint. The credentials, compression and dial options are then those conn was
+// created with, and conn is left open when OpenTelemetry is shut down.
+func WithGRPCConn(conn *grpc.ClientConn) Option {
+	return func(c *Config) {
+		c.grpcConn = conn
+	}
+}
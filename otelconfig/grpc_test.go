@@ -0,0 +1,73 @@
+package otelconfig
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"go.opentelemetry.io/otel"
+)
+
+func TestGRPCDialOptions(t *testing.T) {
+	stopper := dummyGRPCListener()
+	defer stopper()
+
+	var mu sync.Mutex
+	var methods []string
+	interceptor := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		mu.Lock()
+		methods = append(methods, method)
+		mu.Unlock()
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+
+	otelConfig, err := Configure(
+		WithLogger(&testLogger{}),
+		withTestExporters(),
+		WithGRPCDialOptions(grpc.WithUnaryInterceptor(interceptor)),
+		WithMetricsEnabled(false),
+		WithLogsEnabled(false),
+	)
+	require.NoError(t, err)
+	defer otelConfig.Shutdown()
+
+	_, span := otel.Tracer("otelconfig-tests").Start(context.Background(), "test-span")
+	span.End()
+	require.NoError(t, otelConfig.ForceFlush(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"/opentelemetry.proto.collector.trace.v1.TraceService/Export"}, methods)
+}
+
+func TestGRPCConnIsShared(t *testing.T) {
+	traceServer := &dummyTraceServer{}
+	stopper := dummyGRPCListenerWithTraceServer(traceServer)
+	defer stopper()
+
+	conn, err := grpc.NewClient("localhost:4317", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	otelConfig, err := Configure(
+		WithLogger(&testLogger{}),
+		// the endpoints are not dialed when a connection is given
+		WithExporterEndpoint("collector.invalid:1"),
+		WithGRPCConn(conn),
+	)
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("otelconfig-tests").Start(context.Background(), "test-span")
+	span.End()
+	require.NoError(t, otelConfig.ForceFlush(context.Background()))
+	require.NoError(t, otelConfig.ShutdownContext(context.Background()))
+
+	assert.Len(t, traceServer.requests(), 1)
+	assert.NotEqual(t, connectivity.Shutdown, conn.GetState(), "the connection is left open")
+}
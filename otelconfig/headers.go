@@ -0,0 +1,22 @@
+package otelconfig
+
+import (
+	"context"
+)
+
+// HeaderProvider returns headers to add to an export request, such as an Authorization
+// header with a short-lived token. It is called for every request, so it should cache
+// what it returns for as long as it is valid, and it must be safe for concurrent use.
+type HeaderProvider func(ctx context.Context) (map[string]string, error)
+
+// WithHeaderProvider sets a provider of headers for the OTLP exporters of every signal,
+// whose headers are added to the static ones, taking precedence over them. An error from
+// the provider fails the export request, which is then retried like any other failure.
+// The headers are sent as gRPC metadata or HTTP headers, and only over secure connections
+// or to a Unix domain socket: setup fails if an endpoint is insecure. It can't be combined
+// with WithGRPCConn, whose connection has to carry the credentials itself.
+func WithHeaderProvider(provider HeaderProvider) Option {
+	return func(c *Config) {
+		c.headerProvider = provider
+	}
+}
@@ -0,0 +1,197 @@
+package otelconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	"go.opentelemetry.io/otel"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+)
+
+// rotatingHeaderProvider returns a new token on every call, like a provider of short-lived
+// credentials would.
+func rotatingHeaderProvider() HeaderProvider {
+	var calls atomic.Int32
+	return func(context.Context) (map[string]string, error) {
+		return map[string]string{"Authorization": fmt.Sprintf("Bearer token-%d", calls.Add(1))}, nil
+	}
+}
+
+// clientTLSConfig returns the TLS config of a client of ts.
+func clientTLSConfig(ts *httptest.Server) *tls.Config {
+	return ts.Client().Transport.(*http.Transport).TLSClientConfig
+}
+
+func exportSpans(t *testing.T, otelConfig *OtelConfig, count int) {
+	t.Helper()
+	for i := 0; i < count; i++ {
+		_, span := otel.Tracer("otelconfig-tests").Start(context.Background(), "test-span")
+		span.End()
+		require.NoError(t, otelConfig.ForceFlush(context.Background()))
+	}
+}
+
+func TestHeaderProviderOverHTTP(t *testing.T) {
+	for _, protocol := range []Protocol{ProtocolHTTPProto, ProtocolHTTPJSON} {
+		t.Run(string(protocol), func(t *testing.T) {
+			var mu sync.Mutex
+			var headers []http.Header
+			ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				headers = append(headers, r.Header)
+			}))
+			defer ts.Close()
+
+			otelConfig, err := Configure(
+				WithLogger(&testLogger{}),
+				WithExporterEndpoint(ts.URL),
+				WithTLSConfig(clientTLSConfig(ts)),
+				WithExporterProtocol(protocol),
+				WithHeaders(map[string]string{"Authorization": "static", "X-Team": "otel"}),
+				WithHeaderProvider(rotatingHeaderProvider()),
+				WithMetricsEnabled(false),
+				WithLogsEnabled(false),
+			)
+			require.NoError(t, err)
+			defer otelConfig.Shutdown()
+
+			exportSpans(t, otelConfig, 2)
+
+			mu.Lock()
+			defer mu.Unlock()
+			require.Len(t, headers, 2)
+			assert.Equal(t, "Bearer token-1", headers[0].Get("Authorization"))
+			assert.Equal(t, "Bearer token-2", headers[1].Get("Authorization"))
+			assert.Equal(t, "otel", headers[1].Get("X-Team"))
+		})
+	}
+}
+
+func TestHeaderProviderOverGRPC(t *testing.T) {
+	var mu sync.Mutex
+	var tokens []string
+	interceptor := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		mu.Lock()
+		tokens = append(tokens, md.Get("authorization")...)
+		mu.Unlock()
+		return handler(ctx, req)
+	}
+	cert := newTestCertificate(t, "server", &x509.Certificate{
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, nil)
+	keyPair, err := tls.LoadX509KeyPair(cert.certFile, cert.keyFile)
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	roots.AddCert(cert.cert)
+
+	grpcServer := grpc.NewServer(
+		grpc.Creds(credentials.NewServerTLSFromCert(&keyPair)),
+		grpc.UnaryInterceptor(interceptor),
+	)
+	collectortrace.RegisterTraceServiceServer(grpcServer, &dummyTraceServer{})
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go func() {
+		_ = grpcServer.Serve(l)
+	}()
+	defer grpcServer.Stop()
+
+	otelConfig, err := Configure(
+		WithLogger(&testLogger{}),
+		WithTracesExporterEndpoint(l.Addr().String()),
+		WithTLSConfig(&tls.Config{RootCAs: roots}),
+		WithHeaderProvider(rotatingHeaderProvider()),
+		WithMetricsEnabled(false),
+		WithLogsEnabled(false),
+	)
+	require.NoError(t, err)
+	defer otelConfig.Shutdown()
+
+	exportSpans(t, otelConfig, 2)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-2"}, tokens)
+}
+
+func TestHeaderProviderErrorIsRetried(t *testing.T) {
+	var requests atomic.Int32
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer ts.Close()
+
+	var calls atomic.Int32
+	provider := func(context.Context) (map[string]string, error) {
+		if calls.Add(1) == 1 {
+			return nil, errors.New("secret manager unavailable")
+		}
+		return map[string]string{"Authorization": "Bearer token"}, nil
+	}
+
+	otelConfig, err := Configure(
+		WithLogger(&testLogger{}),
+		WithTracesExporterEndpoint(ts.URL),
+		WithTLSConfig(clientTLSConfig(ts)),
+		WithExporterProtocol(ProtocolHTTPProto),
+		WithHeaderProvider(provider),
+		WithRetryInitialInterval(10*time.Millisecond),
+		WithMetricsEnabled(false),
+		WithLogsEnabled(false),
+	)
+	require.NoError(t, err)
+	defer otelConfig.Shutdown()
+
+	exportSpans(t, otelConfig, 1)
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestHeaderProviderWithGRPCConnFails(t *testing.T) {
+	conn, err := grpc.NewClient("localhost:4317", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = Configure(
+		WithLogger(&testLogger{}),
+		WithGRPCConn(conn),
+		WithHeaderProvider(rotatingHeaderProvider()),
+	)
+	assert.EqualError(t, err, "invalid configuration: WithHeaderProvider can't be used with WithGRPCConn")
+}
+
+func TestHeaderProviderRequiresSecureEndpoint(t *testing.T) {
+	for _, protocol := range []Protocol{ProtocolGRPC, ProtocolHTTPProto} {
+		t.Run(string(protocol), func(t *testing.T) {
+			_, err := Configure(
+				WithLogger(&testLogger{}),
+				WithTracesExporterEndpoint("http://collector.example.com:4318"),
+				WithExporterProtocol(protocol),
+				WithHeaderProvider(rotatingHeaderProvider()),
+				WithMetricsEnabled(false),
+				WithLogsEnabled(false),
+			)
+			assert.ErrorContains(t, err, "the headers of the header provider would be sent unencrypted to collector.example.com:4318")
+		})
+	}
+}
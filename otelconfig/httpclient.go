@@ -0,0 +1,71 @@
+package otelconfig
+
+import (
+	"net/http"
+)
+
+// WithHTTPClient sets the client that sends the requests of the HTTP exporters, such as one
+// going through an authenticating proxy. The client is used as is, so the TLS and proxy
+// settings of the exporters don't apply to it.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Config) {
+		c.httpClient = client
+	}
+}
+
+// WithTracesHTTPClient sets the client of the HTTP traces exporter, in place of the one
+// set with WithHTTPClient.
+func WithTracesHTTPClient(client *http.Client) Option {
+	return func(c *Config) {
+		c.tracesHTTPClient = client
+	}
+}
+
+// WithMetricsHTTPClient sets the client of the HTTP metrics exporter, in place of the one
+// set with WithHTTPClient.
+func WithMetricsHTTPClient(client *http.Client) Option {
+	return func(c *Config) {
+		c.metricsHTTPClient = client
+	}
+}
+
+// WithLogsHTTPClient sets the client of the HTTP logs exporter, in place of the one set
+// with WithHTTPClient.
+func WithLogsHTTPClient(client *http.Client) Option {
+	return func(c *Config) {
+		c.logsHTTPClient = client
+	}
+}
+
+// WithHTTPTransport sets the transport of the HTTP exporters' client, such as one signing
+// requests. It is used as is, so the TLS and proxy settings of the exporters don't apply to
+// it, and a client set with WithHTTPClient takes precedence.
+func WithHTTPTransport(transport http.RoundTripper) Option {
+	return func(c *Config) {
+		c.httpTransport = transport
+	}
+}
+
+// WithTracesHTTPTransport sets the transport of the HTTP traces exporter, in place of the
+// one set with WithHTTPTransport.
+func WithTracesHTTPTransport(transport http.RoundTripper) Option {
+	return func(c *Config) {
+		c.tracesHTTPTransport = transport
+	}
+}
+
+// WithMetricsHTTPTransport sets the transport of the HTTP metrics exporter, in place of the
+// one set with WithHTTPTransport.
+func WithMetricsHTTPTransport(transport http.RoundTripper) Option {
+	return func(c *Config) {
+		c.metricsHTTPTransport = transport
+	}
+}
+
+// WithLogsHTTPTransport sets the transport of the HTTP logs exporter, in place of the one
+// set with WithHTTPTransport.
+func WithLogsHTTPTransport(transport http.RoundTripper) Option {
+	return func(c *Config) {
+		c.logsHTTPTransport = transport
+	}
+}
@@ -0,0 +1,107 @@
+package otelconfig
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+)
+
+// signingTransport adds a header to every request, like a transport signing requests would.
+type signingTransport struct {
+	signature string
+}
+
+func (s signingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("X-Signature", s.signature)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestHTTPClientAndTransportPerSignal(t *testing.T) {
+	var mu sync.Mutex
+	signatures := map[string]string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		signatures[r.URL.Path] = r.Header.Get("X-Signature")
+	}))
+	defer ts.Close()
+
+	shutdown, err := ConfigureOpenTelemetry(
+		WithLogsEnabled(true),
+		WithLogger(&testLogger{}),
+		WithExporterEndpoint(ts.URL),
+		WithExporterInsecure(true),
+		WithExporterProtocol(ProtocolHTTPProto),
+		WithHTTPTransport(signingTransport{"transport"}),
+		WithMetricsHTTPClient(&http.Client{Transport: signingTransport{"metrics-client"}}),
+		WithLogsHTTPTransport(signingTransport{"logs-transport"}),
+	)
+	require.NoError(t, err)
+
+	_, span := otel.GetTracerProvider().Tracer("otelconfig-tests").Start(context.Background(), "test-span")
+	span.End()
+	var record otellog.Record
+	record.SetBody(otellog.StringValue("test-log"))
+	global.GetLoggerProvider().Logger("otelconfig-tests").Emit(context.Background(), record)
+	shutdown()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, map[string]string{
+		"/v1/traces":  "transport",
+		"/v1/metrics": "metrics-client",
+		"/v1/logs":    "logs-transport",
+	}, signatures)
+}
+
+func TestHTTPExportersUseProxyFromEnvironment(t *testing.T) {
+	var mu sync.Mutex
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method == http.MethodConnect {
+			proxied = append(proxied, r.Host)
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer proxy.Close()
+	t.Setenv("HTTPS_PROXY", proxy.URL)
+	t.Setenv("NO_PROXY", "logs.example")
+
+	for _, protocol := range []Protocol{ProtocolHTTPProto, ProtocolHTTPJSON} {
+		shutdown, err := ConfigureOpenTelemetry(
+			WithLogger(&testLogger{}),
+			WithExporterProtocol(protocol),
+			WithTracesExporterEndpoint("https://traces.example"),
+			WithLogsExporterEndpoint("https://logs.example"),
+			WithLogsExporterProtocol(ProtocolHTTPProto),
+			WithMetricsEnabled(false),
+			WithRetryEnabled(false),
+			WithExporterTimeout(time.Second),
+		)
+		require.NoError(t, err)
+
+		_, span := otel.GetTracerProvider().Tracer("otelconfig-tests").Start(context.Background(), "test-span")
+		span.End()
+		var record otellog.Record
+		record.SetBody(otellog.StringValue("test-log"))
+		global.GetLoggerProvider().Logger("otelconfig-tests").Emit(context.Background(), record)
+		shutdown()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"traces.example:443", "traces.example:443"}, proxied)
+}
@@ -0,0 +1,149 @@
+package otelconfig
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// oauth2ExpiryDelta is how long before it expires a token is refreshed, so that it doesn't
+// expire while a request is in flight.
+const oauth2ExpiryDelta = 10 * time.Second
+
+// OAuth2ClientCredentials gets access tokens for the exporters from an OAuth2 token
+// endpoint, using the client credentials grant of RFC 6749.
+type OAuth2ClientCredentials struct {
+	// TokenURL is the URL of the token endpoint.
+	TokenURL string
+	// ClientID and ClientSecret authenticate the client with HTTP basic authentication.
+	ClientID     string
+	ClientSecret string
+	// Scopes are the scopes requested, if any.
+	Scopes []string
+	// EndpointParams are added to the parameters of the token request, such as an audience.
+	EndpointParams url.Values
+	// HTTPClient sends the token requests; nil uses http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// HeaderProvider returns a provider of the Authorization header for WithHeaderProvider.
+// It requests a token on first use, and then again shortly before it expires.
+func (cc OAuth2ClientCredentials) HeaderProvider() HeaderProvider {
+	src := &oauth2TokenSource{config: cc}
+	return func(ctx context.Context) (map[string]string, error) {
+		token, err := src.token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{"Authorization": token.tokenType + " " + token.accessToken}, nil
+	}
+}
+
+// oauth2Token is an access token and when it expires; a zero expiry means it doesn't.
+type oauth2Token struct {
+	accessToken string
+	tokenType   string
+	expiry      time.Time
+}
+
+func (t *oauth2Token) valid() bool {
+	return t != nil && (t.expiry.IsZero() || time.Now().Add(oauth2ExpiryDelta).Before(t.expiry))
+}
+
+// oauth2TokenSource caches the token of an OAuth2ClientCredentials, requesting a new one
+// when it is about to expire. Concurrent callers wait for the same request.
+type oauth2TokenSource struct {
+	config  OAuth2ClientCredentials
+	mu      sync.Mutex
+	current *oauth2Token
+}
+
+func (s *oauth2TokenSource) token(ctx context.Context) (*oauth2Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current.valid() {
+		return s.current, nil
+	}
+	token, err := s.config.requestToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.current = token
+	return token, nil
+}
+
+// requestToken requests a new token from the token endpoint.
+func (cc OAuth2ClientCredentials) requestToken(ctx context.Context) (*oauth2Token, error) {
+	params := url.Values{}
+	for k, v := range cc.EndpointParams {
+		params[k] = v
+	}
+	params.Set("grant_type", "client_credentials")
+	if len(cc.Scopes) > 0 {
+		params.Set("scope", strings.Join(cc.Scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cc.TokenURL, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(cc.ClientID), url.QueryEscape(cc.ClientSecret))
+
+	client := cc.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: cannot fetch token: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: cannot fetch token: %w", err)
+	}
+
+	var tr struct {
+		AccessToken      string      `json:"access_token"`
+		TokenType        string      `json:"token_type"`
+		ExpiresIn        json.Number `json:"expires_in"`
+		Error            string      `json:"error"`
+		ErrorDescription string      `json:"error_description"`
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if json.Unmarshal(body, &tr) == nil && tr.Error != "" {
+			return nil, fmt.Errorf("oauth2: cannot fetch token: %s: %s", resp.Status, strings.TrimSpace(tr.Error+" "+tr.ErrorDescription))
+		}
+		return nil, fmt.Errorf("oauth2: cannot fetch token: %s", resp.Status)
+	}
+	if err := json.Unmarshal(body, &tr); err != nil {
+		return nil, fmt.Errorf("oauth2: cannot parse token response: %w", err)
+	}
+	if tr.AccessToken == "" {
+		return nil, errors.New("oauth2: server response missing access_token")
+	}
+
+	token := &oauth2Token{accessToken: tr.AccessToken, tokenType: tr.TokenType}
+	// Servers return the token type in any case, but it is sent back as Bearer.
+	if token.tokenType == "" || strings.EqualFold(token.tokenType, "bearer") {
+		token.tokenType = "Bearer"
+	}
+	if tr.ExpiresIn != "" {
+		seconds, err := tr.ExpiresIn.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("oauth2: invalid expires_in %q", tr.ExpiresIn)
+		}
+		if seconds > 0 {
+			token.expiry = time.Now().Add(time.Duration(seconds) * time.Second)
+		}
+	}
+	return token, nil
+}
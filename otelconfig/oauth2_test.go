@@ -0,0 +1,141 @@
+package otelconfig
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tokenServer is a local OAuth2 token endpoint that issues a new token to every request
+// with the right client credentials.
+type tokenServer struct {
+	*httptest.Server
+	expiresIn int
+
+	mu       sync.Mutex
+	requests []url.Values
+}
+
+func newTokenServer(t *testing.T, expiresIn int) *tokenServer {
+	s := &tokenServer{expiresIn: expiresIn}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveToken))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *tokenServer) serveToken(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	id, secret, ok := r.BasicAuth()
+	if !ok || id != "client" || secret != "s3cr3t" {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":"invalid_client","error_description":"unknown client"}`)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.PostForm)
+	fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"bearer","expires_in":%d}`, len(s.requests), s.expiresIn)
+}
+
+func (s *tokenServer) requestCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+func TestOAuth2ClientCredentials(t *testing.T) {
+	ts := newTokenServer(t, 3600)
+	provider := OAuth2ClientCredentials{
+		TokenURL:       ts.URL,
+		ClientID:       "client",
+		ClientSecret:   "s3cr3t",
+		Scopes:         []string{"telemetry.write", "telemetry.read"},
+		EndpointParams: url.Values{"audience": {"collector"}},
+	}.HeaderProvider()
+
+	for i := 0; i < 2; i++ {
+		headers, err := provider(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"Authorization": "Bearer token-1"}, headers)
+	}
+
+	require.Equal(t, 1, ts.requestCount(), "the token is cached until it expires")
+	assert.Equal(t, url.Values{
+		"grant_type": {"client_credentials"},
+		"scope":      {"telemetry.write telemetry.read"},
+		"audience":   {"collector"},
+	}, ts.requests[0])
+}
+
+func TestOAuth2ClientCredentialsRefreshesExpiringTokens(t *testing.T) {
+	// tokens expiring within oauth2ExpiryDelta are never reused
+	ts := newTokenServer(t, 5)
+	provider := OAuth2ClientCredentials{TokenURL: ts.URL, ClientID: "client", ClientSecret: "s3cr3t"}.HeaderProvider()
+
+	for i := 1; i <= 2; i++ {
+		headers, err := provider(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("Bearer token-%d", i), headers["Authorization"])
+	}
+	assert.Equal(t, 2, ts.requestCount())
+}
+
+func TestOAuth2ClientCredentialsErrors(t *testing.T) {
+	ts := newTokenServer(t, 3600)
+	provider := OAuth2ClientCredentials{TokenURL: ts.URL, ClientID: "client", ClientSecret: "wrong"}.HeaderProvider()
+	_, err := provider(context.Background())
+	assert.EqualError(t, err, "oauth2: cannot fetch token: 401 Unauthorized: invalid_client unknown client")
+
+	empty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"token_type":"bearer"}`)
+	}))
+	defer empty.Close()
+	provider = OAuth2ClientCredentials{TokenURL: empty.URL}.HeaderProvider()
+	_, err = provider(context.Background())
+	assert.EqualError(t, err, "oauth2: server response missing access_token")
+}
+
+func TestOAuth2ClientCredentialsAuthorizeExports(t *testing.T) {
+	tokens := newTokenServer(t, 3600)
+	var mu sync.Mutex
+	var authorizations []string
+	collector := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+	}))
+	defer collector.Close()
+
+	otelConfig, err := Configure(
+		WithLogger(&testLogger{}),
+		WithTracesExporterEndpoint(collector.URL),
+		WithTLSConfig(clientTLSConfig(collector)),
+		WithExporterProtocol(ProtocolHTTPProto),
+		WithHeaderProvider(OAuth2ClientCredentials{
+			TokenURL:     tokens.URL,
+			ClientID:     "client",
+			ClientSecret: "s3cr3t",
+		}.HeaderProvider()),
+		WithMetricsEnabled(false),
+		WithLogsEnabled(false),
+	)
+	require.NoError(t, err)
+	defer otelConfig.Shutdown()
+
+	exportSpans(t, otelConfig, 2)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-1"}, authorizations)
+}
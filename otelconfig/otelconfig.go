@@ -2,21 +2,26 @@ package otelconfig
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"io"
 	"log"
-	"net"
-	"net/url"
+	"log/slog"
+	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/honeycombio/otel-config-go/otelconfig/pipelines"
-	"github.com/sethvargo/go-envconfig"
+	"google.golang.org/grpc"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
@@ -75,6 +80,13 @@ func WithTracesExporterEndpoint(url string) Option {
 	}
 }
 
+// WithLogsExporterEndpoint configures the endpoint for sending logs via OTLP.
+func WithLogsExporterEndpoint(url string) Option {
+	return func(c *Config) {
+		c.LogsExporterEndpoint = url
+	}
+}
+
 // WithServiceName configures a "service.name" resource label.
 func WithServiceName(name string) Option {
 	return func(c *Config) {
@@ -122,6 +134,18 @@ func WithMetricsHeaders(headers map[string]string) Option {
 	}
 }
 
+// WithLogsHeaders configures OTLP logs exporter headers.
+func WithLogsHeaders(headers map[string]string) Option {
+	return func(c *Config) {
+		if c.LogsHeaders == nil {
+			c.LogsHeaders = make(map[string]string)
+		}
+		for k, v := range headers {
+			c.LogsHeaders[k] = v
+		}
+	}
+}
+
 // WithLogLevel configures the logging level for OpenTelemetry.
 func WithLogLevel(loglevel string) Option {
 	return func(c *Config) {
@@ -145,6 +169,48 @@ func WithMetricsExporterInsecure(insecure bool) Option {
 	}
 }
 
+// WithLogsExporterInsecure permits connecting to the
+// logs endpoint without a certificate.
+func WithLogsExporterInsecure(insecure bool) Option {
+	return func(c *Config) {
+		c.LogsExporterEndpointInsecure = insecure
+	}
+}
+
+// WithTLSConfig configures the TLS settings, such as the trusted CAs and client
+// certificates, used to connect to the exporter endpoints. Certificates named by the
+// OTEL_EXPORTER_OTLP_CERTIFICATE, OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE and
+// OTEL_EXPORTER_OTLP_CLIENT_KEY environment variables replace those set here.
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(c *Config) {
+		c.tlsConfig = tlsConfig
+	}
+}
+
+// WithTracesTLSConfig configures the TLS settings used to connect to the traces endpoint,
+// in place of those set with WithTLSConfig.
+func WithTracesTLSConfig(tlsConfig *tls.Config) Option {
+	return func(c *Config) {
+		c.tracesTLSConfig = tlsConfig
+	}
+}
+
+// WithMetricsTLSConfig configures the TLS settings used to connect to the metrics endpoint,
+// in place of those set with WithTLSConfig.
+func WithMetricsTLSConfig(tlsConfig *tls.Config) Option {
+	return func(c *Config) {
+		c.metricsTLSConfig = tlsConfig
+	}
+}
+
+// WithLogsTLSConfig configures the TLS settings used to connect to the logs endpoint,
+// in place of those set with WithTLSConfig.
+func WithLogsTLSConfig(tlsConfig *tls.Config) Option {
+	return func(c *Config) {
+		c.logsTLSConfig = tlsConfig
+	}
+}
+
 // WithResourceAttributes configures attributes on the resource; if the resource
 // already exists, it sets additional attributes or overwrites those already there.
 func WithResourceAttributes(attributes map[string]string) Option {
@@ -200,6 +266,54 @@ func WithTracesEnabled(enabled bool) Option {
 	}
 }
 
+// WithLogsEnabled configures whether logs should be enabled. They are disabled by default.
+func WithLogsEnabled(enabled bool) Option {
+	return func(c *Config) {
+		c.LogsEnabled = &enabled
+	}
+}
+
+// WithSlogDefault configures whether the default slog logger should send records
+// through the logs pipeline. It has no effect if logs are disabled.
+// Note that slog.SetDefault also redirects output from the standard library's log package.
+func WithSlogDefault(enabled bool) Option {
+	return func(c *Config) {
+		c.SlogDefault = enabled
+	}
+}
+
+// WithBatchSpanProcessorScheduleDelay configures the delay between consecutive
+// exports of the batch span processor.
+func WithBatchSpanProcessorScheduleDelay(delay time.Duration) Option {
+	return func(c *Config) {
+		c.BSPScheduleDelay = int(delay.Milliseconds())
+	}
+}
+
+// WithBatchSpanProcessorExportTimeout configures how long the batch span processor
+// waits for an export to complete before cancelling it.
+func WithBatchSpanProcessorExportTimeout(timeout time.Duration) Option {
+	return func(c *Config) {
+		c.BSPExportTimeout = int(timeout.Milliseconds())
+	}
+}
+
+// WithBatchSpanProcessorMaxQueueSize configures the maximum number of spans the
+// batch span processor buffers; spans are dropped once the queue is full.
+func WithBatchSpanProcessorMaxQueueSize(size int) Option {
+	return func(c *Config) {
+		c.BSPMaxQueueSize = size
+	}
+}
+
+// WithBatchSpanProcessorMaxExportBatchSize configures the maximum number of spans
+// the batch span processor sends in a single export.
+func WithBatchSpanProcessorMaxExportBatchSize(size int) Option {
+	return func(c *Config) {
+		c.BSPMaxExportBatchSize = size
+	}
+}
+
 // WithSpanProcessor adds one or more SpanProcessors.
 func WithSpanProcessor(sp ...trace.SpanProcessor) Option {
 	return func(c *Config) {
@@ -207,9 +321,27 @@ func WithSpanProcessor(sp ...trace.SpanProcessor) Option {
 	}
 }
 
+// WithMetricViews adds one or more views, which change the metrics that are recorded.
+func WithMetricViews(views ...metric.View) Option {
+	return func(c *Config) {
+		c.MetricViews = append(c.MetricViews, views...)
+	}
+}
+
+// WithConfigFile configures a YAML file in the OpenTelemetry declarative configuration
+// format to read the configuration from. ${NAME} references to environment variables in
+// the file are substituted. The file is applied on top of the other options, and
+// environment variables still take precedence over it.
+func WithConfigFile(path string) Option {
+	return func(c *Config) {
+		c.ConfigFile = path
+	}
+}
+
 // WithShutdown adds functions that will be called first when the shutdown function is called.
 // They are given a copy of the Config object (which has access to the Logger), and should
-// return an error only in extreme circumstances, as an error return here is immediately fatal.
+// return an error only in extreme circumstances. An error is fatal when shutting down with
+// Shutdown, and is returned to the caller when shutting down with ShutdownContext.
 func WithShutdown(f func(c *Config) error) Option {
 	return func(c *Config) {
 		c.ShutdownFunctions = append(c.ShutdownFunctions, f)
@@ -247,6 +379,203 @@ func WithMetricsExporterProtocol(protocol Protocol) Option {
 	}
 }
 
+// WithLogsExporterProtocol defines the protocol for Logs.
+func WithLogsExporterProtocol(protocol Protocol) Option {
+	return func(c *Config) {
+		c.LogsExporterProtocol = protocol
+	}
+}
+
+// These are the compression values supported by every protocol. The gRPC exporters
+// also accept the name of any other compressor registered with grpc/encoding, such as zstd.
+const (
+	CompressionGzip = pipelines.CompressionGzip
+	CompressionNone = pipelines.CompressionNone
+)
+
+// WithExporterCompression defines the default compression for export requests.
+func WithExporterCompression(compression string) Option {
+	return func(c *Config) {
+		c.ExporterCompression = compression
+	}
+}
+
+// WithTracesExporterCompression defines the compression for Traces.
+func WithTracesExporterCompression(compression string) Option {
+	return func(c *Config) {
+		c.TracesExporterCompression = compression
+	}
+}
+
+// WithMetricsExporterCompression defines the compression for Metrics.
+func WithMetricsExporterCompression(compression string) Option {
+	return func(c *Config) {
+		c.MetricsExporterCompression = compression
+	}
+}
+
+// WithLogsExporterCompression defines the compression for Logs.
+func WithLogsExporterCompression(compression string) Option {
+	return func(c *Config) {
+		c.LogsExporterCompression = compression
+	}
+}
+
+// WithExporterTimeout defines the default maximum time an exporter waits for each
+// export request to complete.
+func WithExporterTimeout(timeout time.Duration) Option {
+	return func(c *Config) {
+		c.ExporterTimeout = int(timeout.Milliseconds())
+	}
+}
+
+// WithTracesExporterTimeout defines the export timeout for Traces.
+func WithTracesExporterTimeout(timeout time.Duration) Option {
+	return func(c *Config) {
+		c.TracesExporterTimeout = int(timeout.Milliseconds())
+	}
+}
+
+// WithMetricsExporterTimeout defines the export timeout for Metrics.
+func WithMetricsExporterTimeout(timeout time.Duration) Option {
+	return func(c *Config) {
+		c.MetricsExporterTimeout = int(timeout.Milliseconds())
+	}
+}
+
+// WithLogsExporterTimeout defines the export timeout for Logs.
+func WithLogsExporterTimeout(timeout time.Duration) Option {
+	return func(c *Config) {
+		c.LogsExporterTimeout = int(timeout.Milliseconds())
+	}
+}
+
+// WithRetryEnabled configures whether exporters retry export requests that fail
+// with a retryable error. Retries are enabled by default.
+func WithRetryEnabled(enabled bool) Option {
+	return func(c *Config) {
+		c.RetryEnabled = &enabled
+	}
+}
+
+// WithRetryInitialInterval configures how long exporters wait before retrying a failed
+// export for the first time. The wait doubles after each attempt.
+func WithRetryInitialInterval(interval time.Duration) Option {
+	return func(c *Config) {
+		c.RetryInitialInterval = int(interval.Milliseconds())
+	}
+}
+
+// WithRetryMaxInterval configures the longest exporters wait between two retries.
+func WithRetryMaxInterval(interval time.Duration) Option {
+	return func(c *Config) {
+		c.RetryMaxInterval = int(interval.Milliseconds())
+	}
+}
+
+// WithRetryMaxElapsedTime configures how long exporters keep retrying an export before
+// giving up and dropping the data.
+func WithRetryMaxElapsedTime(elapsed time.Duration) Option {
+	return func(c *Config) {
+		c.RetryMaxElapsedTime = int(elapsed.Milliseconds())
+	}
+}
+
+// These are the exporters that can be passed to WithTracesExporters, WithMetricsExporters
+// and WithLogsExporters.
+const (
+	ExporterOTLP       = pipelines.ExporterOTLP
+	ExporterConsole    = pipelines.ExporterConsole
+	ExporterNone       = pipelines.ExporterNone
+	ExporterFile       = pipelines.ExporterFile
+	ExporterPrometheus = pipelines.ExporterPrometheus
+)
+
+// WithTracesExporters configures the exporters spans are sent to. Spans are sent to
+// every exporter in the list, and ExporterNone on its own exports nothing.
+func WithTracesExporters(exporters []string) Option {
+	return func(c *Config) {
+		c.TracesExporters = exporters
+	}
+}
+
+// WithMetricsExporters configures the exporters metrics are sent to. Metrics can also be
+// scraped by Prometheus by including ExporterPrometheus.
+func WithMetricsExporters(exporters []string) Option {
+	return func(c *Config) {
+		c.MetricsExporters = exporters
+	}
+}
+
+// WithLogsExporters configures the exporters log records are sent to.
+func WithLogsExporters(exporters []string) Option {
+	return func(c *Config) {
+		c.LogsExporters = exporters
+	}
+}
+
+// WithPrometheusHost configures the host the Prometheus exporter serves /metrics on.
+func WithPrometheusHost(host string) Option {
+	return func(c *Config) {
+		c.PrometheusHost = host
+	}
+}
+
+// WithPrometheusPort configures the port the Prometheus exporter serves /metrics on.
+func WithPrometheusPort(port int) Option {
+	return func(c *Config) {
+		c.PrometheusPort = port
+	}
+}
+
+// WithFileExporterPath configures the file the file exporter appends telemetry to,
+// one OTLP/JSON export request per line.
+func WithFileExporterPath(path string) Option {
+	return func(c *Config) {
+		c.FileExporterPath = path
+	}
+}
+
+// WithFileExporterMaxBytes configures the size the exporter file may grow to before it
+// is rotated. Zero disables rotation.
+func WithFileExporterMaxBytes(maxBytes int64) Option {
+	return func(c *Config) {
+		c.FileExporterMaxBytes = maxBytes
+	}
+}
+
+// WithFileExporterMaxBackups configures how many rotated exporter files are kept.
+func WithFileExporterMaxBackups(maxBackups int) Option {
+	return func(c *Config) {
+		c.FileExporterMaxBackups = maxBackups
+	}
+}
+
+// WithTracesQueueDir enables a persistent queue for exporting spans over OTLP. Batches
+// that can't be exported are spooled to dir, and replayed in order once the endpoint
+// recovers, so that spans aren't lost while it is unreachable.
+func WithTracesQueueDir(dir string) Option {
+	return func(c *Config) {
+		c.TracesQueueDir = dir
+	}
+}
+
+// WithTracesQueueMaxBytes configures the size the persistent queue may grow to before the
+// oldest batches are dropped. Zero means no limit.
+func WithTracesQueueMaxBytes(maxBytes int64) Option {
+	return func(c *Config) {
+		c.TracesQueueMaxBytes = maxBytes
+	}
+}
+
+// WithConsoleWriter configures where the console exporter writes to instead of
+// standard output.
+func WithConsoleWriter(w io.Writer) Option {
+	return func(c *Config) {
+		c.consoleWriter = w
+	}
+}
+
 // WithSampler configures the Sampler to use when processing trace spans.
 func WithSampler(sampler trace.Sampler) Option {
 	return func(c *Config) {
@@ -273,7 +602,15 @@ func WithLogger(logger Logger) Option {
 }
 
 type defaultLogger struct {
-	logLevel string
+	// logLevel holds a string. It is atomic, as newConfig sets it when the config file is
+	// reloaded, while other goroutines are logging.
+	logLevel atomic.Value
+}
+
+func newDefaultLogger(logLevel string) *defaultLogger {
+	l := &defaultLogger{}
+	l.logLevel.Store(logLevel)
+	return l
 }
 
 func (l *defaultLogger) Fatalf(format string, v ...interface{}) {
@@ -282,12 +619,12 @@ func (l *defaultLogger) Fatalf(format string, v ...interface{}) {
 }
 
 func (l *defaultLogger) Debugf(format string, v ...interface{}) {
-	if l.logLevel == "debug" {
+	if l.logLevel.Load() == "debug" {
 		log.Printf(format, v...)
 	}
 }
 
-var defLogger Logger = &defaultLogger{logLevel: "info"}
+var defLogger Logger = newDefaultLogger("info")
 
 type defaultHandler struct {
 	logger Logger
@@ -313,22 +650,90 @@ type Config struct {
 	MetricsExporterEndpointInsecure bool              `env:"OTEL_EXPORTER_OTLP_METRICS_INSECURE"`
 	MetricsEnabled                  *bool             `env:"OTEL_METRICS_ENABLED,default=true"`
 	MetricsReportingPeriod          string            `env:"OTEL_EXPORTER_OTLP_METRICS_PERIOD,overwrite,default=30s"`
+	LogsExporterEndpoint            string            `env:"OTEL_EXPORTER_OTLP_LOGS_ENDPOINT,overwrite"`
+	LogsExporterEndpointInsecure    bool              `env:"OTEL_EXPORTER_OTLP_LOGS_INSECURE"`
+	LogsEnabled                     *bool             `env:"OTEL_LOGS_ENABLED,default=false"`
 	LogLevel                        string            `env:"OTEL_LOG_LEVEL,overwrite,default=info"`
 	Propagators                     []string          `env:"OTEL_PROPAGATORS,overwrite,default=tracecontext,baggage"`
 	ExporterProtocol                Protocol          `env:"OTEL_EXPORTER_OTLP_PROTOCOL,overwrite,default=grpc"`
 	TracesExporterProtocol          Protocol          `env:"OTEL_EXPORTER_OTLP_TRACES_PROTOCOL,overwrite"`
 	MetricsExporterProtocol         Protocol          `env:"OTEL_EXPORTER_OTLP_METRICS_PROTOCOL,overwrite"`
+	LogsExporterProtocol            Protocol          `env:"OTEL_EXPORTER_OTLP_LOGS_PROTOCOL,overwrite"`
 	Headers                         map[string]string `env:"OTEL_EXPORTER_OTLP_HEADERS,overwrite,separator=="`
 	TracesHeaders                   map[string]string `env:"OTEL_EXPORTER_OTLP_TRACES_HEADERS,overwrite,separator=="`
 	MetricsHeaders                  map[string]string `env:"OTEL_EXPORTER_OTLP_METRICS_HEADERS,overwrite,separator=="`
+	LogsHeaders                     map[string]string `env:"OTEL_EXPORTER_OTLP_LOGS_HEADERS,overwrite,separator=="`
+	ExporterCertificate             string            `env:"OTEL_EXPORTER_OTLP_CERTIFICATE,overwrite"`
+	ExporterClientCertificate       string            `env:"OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE,overwrite"`
+	ExporterClientKey               string            `env:"OTEL_EXPORTER_OTLP_CLIENT_KEY,overwrite"`
+	TracesCertificate               string            `env:"OTEL_EXPORTER_OTLP_TRACES_CERTIFICATE,overwrite"`
+	TracesClientCertificate         string            `env:"OTEL_EXPORTER_OTLP_TRACES_CLIENT_CERTIFICATE,overwrite"`
+	TracesClientKey                 string            `env:"OTEL_EXPORTER_OTLP_TRACES_CLIENT_KEY,overwrite"`
+	MetricsCertificate              string            `env:"OTEL_EXPORTER_OTLP_METRICS_CERTIFICATE,overwrite"`
+	MetricsClientCertificate        string            `env:"OTEL_EXPORTER_OTLP_METRICS_CLIENT_CERTIFICATE,overwrite"`
+	MetricsClientKey                string            `env:"OTEL_EXPORTER_OTLP_METRICS_CLIENT_KEY,overwrite"`
+	LogsCertificate                 string            `env:"OTEL_EXPORTER_OTLP_LOGS_CERTIFICATE,overwrite"`
+	LogsClientCertificate           string            `env:"OTEL_EXPORTER_OTLP_LOGS_CLIENT_CERTIFICATE,overwrite"`
+	LogsClientKey                   string            `env:"OTEL_EXPORTER_OTLP_LOGS_CLIENT_KEY,overwrite"`
+	ExporterCompression             string            `env:"OTEL_EXPORTER_OTLP_COMPRESSION,overwrite,default=gzip"`
+	TracesExporterCompression       string            `env:"OTEL_EXPORTER_OTLP_TRACES_COMPRESSION,overwrite"`
+	MetricsExporterCompression      string            `env:"OTEL_EXPORTER_OTLP_METRICS_COMPRESSION,overwrite"`
+	LogsExporterCompression         string            `env:"OTEL_EXPORTER_OTLP_LOGS_COMPRESSION,overwrite"`
+	ExporterTimeout                 int               `env:"OTEL_EXPORTER_OTLP_TIMEOUT,overwrite,default=10000"`
+	TracesExporterTimeout           int               `env:"OTEL_EXPORTER_OTLP_TRACES_TIMEOUT,overwrite"`
+	MetricsExporterTimeout          int               `env:"OTEL_EXPORTER_OTLP_METRICS_TIMEOUT,overwrite"`
+	LogsExporterTimeout             int               `env:"OTEL_EXPORTER_OTLP_LOGS_TIMEOUT,overwrite"`
+	RetryEnabled                    *bool             `env:"OTEL_EXPORTER_OTLP_RETRY_ENABLED,overwrite,default=true"`
+	RetryInitialInterval            int               `env:"OTEL_EXPORTER_OTLP_RETRY_INITIAL_INTERVAL,overwrite"`
+	RetryMaxInterval                int               `env:"OTEL_EXPORTER_OTLP_RETRY_MAX_INTERVAL,overwrite"`
+	RetryMaxElapsedTime             int               `env:"OTEL_EXPORTER_OTLP_RETRY_MAX_ELAPSED_TIME,overwrite"`
+	TracesExporters                 []string          `env:"OTEL_TRACES_EXPORTER,overwrite,default=otlp"`
+	MetricsExporters                []string          `env:"OTEL_METRICS_EXPORTER,overwrite,default=otlp"`
+	LogsExporters                   []string          `env:"OTEL_LOGS_EXPORTER,overwrite,default=otlp"`
+	PrometheusHost                  string            `env:"OTEL_EXPORTER_PROMETHEUS_HOST,overwrite,default=localhost"`
+	PrometheusPort                  int               `env:"OTEL_EXPORTER_PROMETHEUS_PORT,overwrite,default=9464"`
+	FileExporterPath                string            `env:"OTEL_EXPORTER_FILE_PATH,overwrite"`
+	FileExporterMaxBytes            int64             `env:"OTEL_EXPORTER_FILE_MAX_BYTES,overwrite,default=104857600"`
+	FileExporterMaxBackups          int               `env:"OTEL_EXPORTER_FILE_MAX_BACKUPS,overwrite,default=3"`
+	TracesQueueDir                  string            `env:"OTEL_EXPORTER_OTLP_TRACES_QUEUE_DIR,overwrite"`
+	TracesQueueMaxBytes             int64             `env:"OTEL_EXPORTER_OTLP_TRACES_QUEUE_MAX_BYTES,overwrite,default=104857600"`
 	ResourceAttributes              map[string]string `env:"OTEL_RESOURCE_ATTRIBUTES,overwrite,separator=="`
+	TracesSampler                   string            `env:"OTEL_TRACES_SAMPLER,overwrite"`
+	TracesSamplerArg                string            `env:"OTEL_TRACES_SAMPLER_ARG,overwrite"`
+	BSPScheduleDelay                int               `env:"OTEL_BSP_SCHEDULE_DELAY,overwrite"`
+	BSPExportTimeout                int               `env:"OTEL_BSP_EXPORT_TIMEOUT,overwrite"`
+	BSPMaxQueueSize                 int               `env:"OTEL_BSP_MAX_QUEUE_SIZE,overwrite"`
+	BSPMaxExportBatchSize           int               `env:"OTEL_BSP_MAX_EXPORT_BATCH_SIZE,overwrite"`
+	ConfigFile                      string            `env:"OTEL_CONFIG_FILE,overwrite"`
+	SlogDefault                     bool
+	RedactedHeaders                 []string
 	SpanProcessors                  []trace.SpanProcessor
+	MetricViews                     []metric.View `json:"-"`
 	Sampler                         trace.Sampler
 	ResourceOptions                 []resource.Option
 	Resource                        *resource.Resource
 	Logger                          Logger                  `json:"-"`
 	ShutdownFunctions               []func(c *Config) error `json:"-"`
 	errorHandler                    otel.ErrorHandler
+	configErrorHandler              func(*ConfigError) error
+	tlsConfig                       *tls.Config
+	tracesTLSConfig                 *tls.Config
+	metricsTLSConfig                *tls.Config
+	logsTLSConfig                   *tls.Config
+	httpClient                      *http.Client
+	tracesHTTPClient                *http.Client
+	metricsHTTPClient               *http.Client
+	logsHTTPClient                  *http.Client
+	httpTransport                   http.RoundTripper
+	tracesHTTPTransport             http.RoundTripper
+	metricsHTTPTransport            http.RoundTripper
+	logsHTTPTransport               http.RoundTripper
+	grpcDialOptions                 []grpc.DialOption
+	grpcConn                        *grpc.ClientConn
+	headerProvider                  HeaderProvider
+	consoleWriter                   io.Writer
+	reloadSignals                   []os.Signal
+	configFileWatchInterval         time.Duration
 }
 
 func newConfig(opts ...Option) (*Config, error) {
@@ -336,6 +741,7 @@ func newConfig(opts ...Option) (*Config, error) {
 		Headers:            map[string]string{},
 		TracesHeaders:      map[string]string{},
 		MetricsHeaders:     map[string]string{},
+		LogsHeaders:        map[string]string{},
 		ResourceAttributes: map[string]string{},
 		Logger:             defLogger,
 		errorHandler:       &defaultHandler{logger: defLogger},
@@ -357,17 +763,38 @@ func newConfig(opts ...Option) (*Config, error) {
 		opt(c)
 	}
 
+	// the config file is read before the environment variables are applied, so that they
+	// still take precedence over it
+	if path := firstNonEmpty(os.Getenv("OTEL_CONFIG_FILE"), c.ConfigFile); path != "" {
+		c.ConfigFile = path
+		if err := loadConfigFile(c, path); err != nil {
+			return nil, err
+		}
+	}
+
+	// a connection made by the caller can't carry headers it doesn't know about
+	if c.headerProvider != nil && c.grpcConn != nil {
+		return nil, errors.New("invalid configuration: WithHeaderProvider can't be used with WithGRPCConn")
+	}
+
 	// If using defaultLogger, update it's LogLevel to configured level
 	if l, ok := c.Logger.(*defaultLogger); ok {
-		l.logLevel = c.LogLevel
+		l.logLevel.Store(c.LogLevel)
 	}
 
 	// apply environment variables last to override any vendor or user options
-	envError := envconfig.Process(context.Background(), c)
-	if envError != nil {
-		c.Logger.Fatalf("environment error: %v", envError)
-		// if our logger implementation doesn't os.Exit, we want to return here
-		return nil, fmt.Errorf("environment error: %w", envError)
+	if err := processEnvironment(c); err != nil {
+		return nil, err
+	}
+
+	// a sampler named in the environment replaces one configured in code
+	if c.TracesSampler != "" {
+		sampler, err := newSampler(c.TracesSampler, c.TracesSamplerArg)
+		if err == nil {
+			c.Sampler = sampler
+		} else if err := c.handleConfigError(err); err != nil {
+			return nil, err
+		}
 	}
 
 	var err error
@@ -386,8 +813,7 @@ func newConfig(opts ...Option) (*Config, error) {
 
 // OtelConfig is the object we're here for; it implements the initialization of Open Telemetry.
 type OtelConfig struct {
-	config        *Config
-	shutdownFuncs []func() error
+	sdk *SDK
 }
 
 func newResource(c *Config) (*resource.Resource, error) {
@@ -430,6 +856,45 @@ func newResource(c *Config) (*resource.Resource, error) {
 	)
 }
 
+// newSampler builds one of the samplers defined by the OTEL_TRACES_SAMPLER specification.
+// The arg is only used by the ratio based samplers, and defaults to 1.0 if not set.
+func newSampler(name string, arg string) (trace.Sampler, *ConfigError) {
+	ratio := 1.0
+	if arg != "" && (name == "traceidratio" || name == "parentbased_traceidratio") {
+		var err error
+		ratio, err = strconv.ParseFloat(strings.TrimSpace(arg), 64)
+		if err != nil || ratio < 0 || ratio > 1 {
+			return nil, &ConfigError{
+				Variable: "OTEL_TRACES_SAMPLER_ARG",
+				Value:    arg,
+				Err:      fmt.Errorf("sampler argument %q must be a number between 0 and 1", arg),
+			}
+		}
+	}
+
+	switch name {
+	case "always_on":
+		return trace.AlwaysSample(), nil
+	case "always_off":
+		return trace.NeverSample(), nil
+	case "traceidratio":
+		return trace.TraceIDRatioBased(ratio), nil
+	case "parentbased_always_on":
+		return trace.ParentBased(trace.AlwaysSample()), nil
+	case "parentbased_always_off":
+		return trace.ParentBased(trace.NeverSample()), nil
+	case "parentbased_traceidratio":
+		return trace.ParentBased(trace.TraceIDRatioBased(ratio)), nil
+	default:
+		return nil, &ConfigError{
+			Variable: "OTEL_TRACES_SAMPLER",
+			Value:    name,
+			Err: fmt.Errorf("unsupported sampler %q. Supported options: "+
+				"always_on,always_off,traceidratio,parentbased_always_on,parentbased_always_off,parentbased_traceidratio", name),
+		}
+	}
+}
+
 type serviceVersionDetector struct{}
 
 var _ resource.Detector = serviceVersionDetector{}
@@ -442,143 +907,206 @@ func (serviceVersionDetector) Detect(ctx context.Context) (*resource.Resource, e
 	return resource.NewSchemaless(semconv.ServiceVersionKey.String(serviceVersion)), nil
 }
 
-type setupFunc func(*Config) (func() error, error)
+type setupFunc func(*Config, *SDK) error
 
-// ensures that a port is set on the given host string, or adds the default port.
-func ensurePort(host string, defaultPort string) string {
-	ix := strings.Index(host, ":")
-	switch {
-	case ix < 0:
-		return host + ":" + defaultPort
-	case ix == len(host)-1:
-		return host + defaultPort
-	default:
-		return host
+func (c *Config) getTracesHeaders() map[string]string {
+	// combine generic and traces headers
+	headers := map[string]string{}
+	for key, value := range c.Headers {
+		headers[key] = value
+	}
+	for key, value := range c.TracesHeaders {
+		headers[key] = value
 	}
+	return headers
 }
 
-// sets default secure port 443 if no port provided
-// used when protocol is grpc and provided endpoint is prepended with https://
-func secureGrpcPort(endpoint string) string {
-	u, err := url.Parse(endpoint)
-	if err != nil {
-		return endpoint
+func (c *Config) getMetricsHeaders() map[string]string {
+	// combine generic and metrics headers
+	headers := map[string]string{}
+	for key, value := range c.Headers {
+		headers[key] = value
 	}
-	var host, port string
-	// if port is provided, keep it as is
-	if u.Port() != "" {
-		host, port, err = net.SplitHostPort(u.Host)
-		if err != nil {
-			return endpoint
-		}
-	} else {
-		// set port 443 if not provided
-		host = u.Host
-		port = SSLDefaultPort
+	for key, value := range c.MetricsHeaders {
+		headers[key] = value
 	}
-	endpoint = fmt.Sprintf("%s:%s", host, port)
-	return endpoint
+	return headers
 }
 
-// trim http scheme from endpoint for proper parsing
-func trimHttpScheme(url string, protocol Protocol) string {
-	switch {
-	case strings.HasPrefix(url, "https://"):
-		if protocol == ProtocolGRPC {
-			url = secureGrpcPort(url)
-		}
-		return strings.TrimPrefix(url, "https://")
-	case strings.HasPrefix(url, "http://"):
-		return strings.TrimPrefix(url, "http://")
-	default:
-		return url
+func (c *Config) getLogsHeaders() map[string]string {
+	// combine generic and logs headers
+	headers := map[string]string{}
+	for key, value := range c.Headers {
+		headers[key] = value
+	}
+	for key, value := range c.LogsHeaders {
+		headers[key] = value
 	}
+	return headers
 }
 
-func (c *Config) getTracesEndpoint() (string, bool) {
-	// use traces specific endpoint, falling back to generic version if not set
-	if c.TracesExporterEndpoint == "" {
-		// if generic endpoint is empty, traces is disabled
-		if c.ExporterEndpoint == "" {
-			return "", false
-		}
-		c.TracesExporterEndpoint = c.ExporterEndpoint
-		c.TracesExporterEndpointInsecure = c.ExporterEndpointInsecure
+func (c *Config) getTracesTLSConfig() (*tls.Config, error) {
+	// use traces specific settings, falling back to generic versions if not set
+	return newTLSConfig(
+		firstNonNil(c.tracesTLSConfig, c.tlsConfig),
+		signalSetting("TRACES", "CERTIFICATE", c.TracesCertificate, c.ExporterCertificate),
+		signalSetting("TRACES", "CLIENT_CERTIFICATE", c.TracesClientCertificate, c.ExporterClientCertificate),
+		signalSetting("TRACES", "CLIENT_KEY", c.TracesClientKey, c.ExporterClientKey),
+	)
+}
+
+func (c *Config) getMetricsTLSConfig() (*tls.Config, error) {
+	// use metrics specific settings, falling back to generic versions if not set
+	return newTLSConfig(
+		firstNonNil(c.metricsTLSConfig, c.tlsConfig),
+		signalSetting("METRICS", "CERTIFICATE", c.MetricsCertificate, c.ExporterCertificate),
+		signalSetting("METRICS", "CLIENT_CERTIFICATE", c.MetricsClientCertificate, c.ExporterClientCertificate),
+		signalSetting("METRICS", "CLIENT_KEY", c.MetricsClientKey, c.ExporterClientKey),
+	)
+}
+
+func (c *Config) getLogsTLSConfig() (*tls.Config, error) {
+	// use logs specific settings, falling back to generic versions if not set
+	return newTLSConfig(
+		firstNonNil(c.logsTLSConfig, c.tlsConfig),
+		signalSetting("LOGS", "CERTIFICATE", c.LogsCertificate, c.ExporterCertificate),
+		signalSetting("LOGS", "CLIENT_CERTIFICATE", c.LogsClientCertificate, c.ExporterClientCertificate),
+		signalSetting("LOGS", "CLIENT_KEY", c.LogsClientKey, c.ExporterClientKey),
+	)
+}
+
+// newTLSConfig adds the CA certificate and client key pair read from the given PEM files
+// to a copy of base. It returns nil if there are no TLS settings, so that the exporters
+// use their defaults. A file that can't be used is reported as a *ConfigError.
+func newTLSConfig(base *tls.Config, certificate, clientCertificate, clientKey setting) (*tls.Config, error) {
+	if base == nil && certificate.value == "" && clientCertificate.value == "" && clientKey.value == "" {
+		return nil, nil
 	}
 
-	// use traces specific protocol, falling back to generic version if not set
-	if c.TracesExporterProtocol == "" {
-		c.TracesExporterProtocol = c.ExporterProtocol
+	tlsConfig := &tls.Config{}
+	if base != nil {
+		tlsConfig = base.Clone()
 	}
 
-	// helper function - if using grpc and prepending with http, drop the http scheme
-	if c.TracesExporterProtocol == ProtocolGRPC {
-		c.TracesExporterEndpoint = trimHttpScheme(c.TracesExporterEndpoint, ProtocolGRPC)
+	if certificate.value != "" {
+		pem, err := os.ReadFile(certificate.value)
+		if err != nil {
+			return nil, certificate.invalid(fmt.Errorf("failed to read certificate: %w", err))
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, certificate.invalid(errors.New("no PEM certificates found"))
+		}
+		tlsConfig.RootCAs = roots
 	}
 
-	// use traces specific port, falling back to generic version if not set
-	port := GRPCDefaultPort
-	if c.TracesExporterProtocol != ProtocolGRPC {
-		port = HTTPDefaultPort
+	if clientCertificate.value != "" || clientKey.value != "" {
+		// the variable that is set is reported, as the missing one could be either of two
+		if clientCertificate.value == "" {
+			return nil, clientKey.invalid(errors.New("client certificate and client key must be set together"))
+		}
+		if clientKey.value == "" {
+			return nil, clientCertificate.invalid(errors.New("client certificate and client key must be set together"))
+		}
+		cert, err := tls.LoadX509KeyPair(clientCertificate.value, clientKey.value)
+		if err != nil {
+			return nil, clientCertificate.invalid(fmt.Errorf("failed to load client certificate: %w", err))
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
 	}
-	return ensurePort(c.TracesExporterEndpoint, port), c.TracesExporterEndpointInsecure
+	return tlsConfig, nil
 }
 
-func (c *Config) getMetricsEndpoint() (string, bool) {
-	// use metrics specific endpoint, falling back to generic version if not set
-	if c.MetricsExporterEndpoint == "" {
-		// if generic endpoint is empty, traces is disabled
-		if c.ExporterEndpoint == "" {
-			return "", false
+func firstNonNil[T any](values ...*T) *T {
+	for _, v := range values {
+		if v != nil {
+			return v
 		}
-		c.MetricsExporterEndpoint = c.ExporterEndpoint
-		c.MetricsExporterEndpointInsecure = c.ExporterEndpointInsecure
 	}
+	return nil
+}
 
-	// If a Metrics-specific protocol wasn't specified, then use the generic one,
-	// which has a default value.
-	if c.MetricsExporterProtocol == "" {
-		c.MetricsExporterProtocol = c.ExporterProtocol
+func firstNonNilTransport(values ...http.RoundTripper) http.RoundTripper {
+	for _, v := range values {
+		if v != nil {
+			return v
+		}
 	}
+	return nil
+}
 
-	if c.MetricsExporterProtocol == ProtocolGRPC {
-		c.MetricsExporterEndpoint = trimHttpScheme(c.MetricsExporterEndpoint, ProtocolGRPC)
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
 	}
+	return ""
+}
 
-	// use metrics specific port, failling back to generic version if not set
-	port := HTTPDefaultPort
-	if c.MetricsExporterProtocol == ProtocolGRPC {
-		port = GRPCDefaultPort
+func firstNonZero(values ...int) int {
+	for _, v := range values {
+		if v != 0 {
+			return v
+		}
 	}
-	return ensurePort(c.MetricsExporterEndpoint, port), c.MetricsExporterEndpointInsecure
+	return 0
 }
 
-func (c *Config) getTracesHeaders() map[string]string {
-	// combine generic and traces headers
-	headers := map[string]string{}
-	for key, value := range c.Headers {
-		headers[key] = value
+// exportTimeout converts a timeout in milliseconds, falling back to the generic timeout.
+func (c *Config) exportTimeout(timeout int) time.Duration {
+	return time.Duration(firstNonZero(timeout, c.ExporterTimeout)) * time.Millisecond
+}
+
+// retryDisabled reports whether retries have been turned off.
+func (c *Config) retryDisabled() bool {
+	return c.RetryEnabled != nil && !*c.RetryEnabled
+}
+
+// usesExporter reports whether any signal is configured to send to the named exporter.
+func (c *Config) usesExporter(name string) bool {
+	for _, exporters := range [][]string{c.TracesExporters, c.MetricsExporters, c.LogsExporters} {
+		if listsExporter(exporters, name) {
+			return true
+		}
 	}
-	for key, value := range c.TracesHeaders {
-		headers[key] = value
+	return false
+}
+
+// listsExporter reports whether exporters include the named exporter.
+func listsExporter(exporters []string, name string) bool {
+	for _, exporter := range exporters {
+		if strings.TrimSpace(exporter) == name {
+			return true
+		}
 	}
-	return headers
+	return false
 }
 
-func (c *Config) getMetricsHeaders() map[string]string {
-	// combine generic and metrics headers
-	headers := map[string]string{}
-	for key, value := range c.Headers {
-		headers[key] = value
+// sendsToOTLP reports whether a signal with these exporters sends to an OTLP endpoint,
+// which is the default when none are listed. Otherwise its endpoint is neither resolved
+// nor required.
+func sendsToOTLP(exporters []string) bool {
+	return len(exporters) == 0 || listsExporter(exporters, ExporterOTLP)
+}
+
+// setupFileWriter opens the file shared by the file exporters of every signal.
+func setupFileWriter(c *Config, sdk *SDK) error {
+	if !c.usesExporter(ExporterFile) {
+		return nil
 	}
-	for key, value := range c.MetricsHeaders {
-		headers[key] = value
+	if c.FileExporterPath == "" {
+		return errors.New("invalid configuration: the file exporter requires a path")
 	}
-	return headers
+	w, err := pipelines.NewFileWriter(c.FileExporterPath, c.FileExporterMaxBytes, c.FileExporterMaxBackups)
+	if err != nil {
+		return fmt.Errorf("failed to open exporter file: %w", err)
+	}
+	sdk.fileWriter = w
+	return nil
 }
 
-func setupTracing(c *Config) (func() error, error) {
-	endpoint, insecure := c.getTracesEndpoint()
+func setupTracing(c *Config, sdk *SDK) error {
 	var enabled bool
 	if c.TracesEnabled == nil {
 		enabled = true
@@ -587,27 +1115,93 @@ func setupTracing(c *Config) (func() error, error) {
 	}
 	if !enabled {
 		c.Logger.Debugf("tracing is disabled by configuration: enabled set to false")
-		return nil, nil
+		return nil
 	}
-	if endpoint == "" {
-		c.Logger.Debugf("tracing is disabled by configuration: no endpoint set")
-		return nil, nil
+	if sendsToOTLP(c.TracesExporters) {
+		endpoint, err := c.getTracesEndpoint()
+		if err != nil {
+			return err
+		}
+		if endpoint.Host == "" {
+			c.Logger.Debugf("tracing is disabled by configuration: no endpoint set")
+			return nil
+		}
+	}
+
+	pipelineConfig, err := tracesPipelineConfig(c, sdk)
+	if err != nil {
+		return err
+	}
+
+	propagator, err := pipelines.NewPropagator(c.Propagators)
+	if err != nil {
+		return err
+	}
+	sdk.Propagator = propagator
+
+	sdk.sampler = &reloadableSampler{}
+	sdk.sampler.set(c.Sampler)
+	sdk.tracesReloader = &pipelines.Reloader{}
+	pipelineConfig.Sampler = sdk.sampler
+	pipelineConfig.Reloader = sdk.tracesReloader
+	sdk.TracerProvider, err = pipelines.NewTracerProvider(pipelineConfig)
+	return err
+}
+
+// tracesPipelineConfig converts c into the configuration of the traces pipeline.
+func tracesPipelineConfig(c *Config, sdk *SDK) (pipelines.PipelineConfig, error) {
+	var endpoint exporterEndpoint
+	var tlsConfig *tls.Config
+	compression := signalSetting("TRACES", "COMPRESSION", c.TracesExporterCompression, c.ExporterCompression)
+	if sendsToOTLP(c.TracesExporters) {
+		var err error
+		if endpoint, err = c.getTracesEndpoint(); err != nil {
+			return pipelines.PipelineConfig{}, err
+		}
+		if tlsConfig, err = c.getTracesTLSConfig(); err != nil {
+			return pipelines.PipelineConfig{}, err
+		}
+		if err = pipelines.CheckCompression(pipelines.Protocol(c.TracesExporterProtocol), compression.value); err != nil {
+			return pipelines.PipelineConfig{}, compression.invalid(err)
+		}
 	}
 
-	return pipelines.NewTracePipeline(pipelines.PipelineConfig{
-		Protocol:       pipelines.Protocol(c.TracesExporterProtocol),
-		Endpoint:       trimHttpScheme(endpoint, c.TracesExporterProtocol),
-		Insecure:       insecure,
-		Headers:        c.getTracesHeaders(),
-		Resource:       c.Resource,
-		Propagators:    c.Propagators,
-		SpanProcessors: c.SpanProcessors,
-		Sampler:        c.Sampler,
-	})
+	return pipelines.PipelineConfig{
+		Protocol:              pipelines.Protocol(c.TracesExporterProtocol),
+		Endpoint:              endpoint.Host,
+		Socket:                endpoint.Socket,
+		URLPath:               endpoint.Path,
+		Insecure:              endpoint.Insecure,
+		Headers:               c.getTracesHeaders(),
+		Resource:              c.Resource,
+		Propagators:           c.Propagators,
+		SpanProcessors:        c.SpanProcessors,
+		Sampler:               c.Sampler,
+		BSPScheduleDelay:      time.Duration(c.BSPScheduleDelay) * time.Millisecond,
+		BSPExportTimeout:      time.Duration(c.BSPExportTimeout) * time.Millisecond,
+		BSPMaxQueueSize:       c.BSPMaxQueueSize,
+		BSPMaxExportBatchSize: c.BSPMaxExportBatchSize,
+		TLSConfig:             tlsConfig,
+		HTTPClient:            firstNonNil(c.tracesHTTPClient, c.httpClient),
+		HTTPTransport:         firstNonNilTransport(c.tracesHTTPTransport, c.httpTransport),
+		GRPCDialOptions:       c.grpcDialOptions,
+		GRPCConn:              c.grpcConn,
+		HeaderProvider:        pipelines.HeaderProvider(c.headerProvider),
+		Compression:           compression.value,
+		Timeout:               c.exportTimeout(c.TracesExporterTimeout),
+		RetryDisabled:         c.retryDisabled(),
+		RetryInitialInterval:  time.Duration(c.RetryInitialInterval) * time.Millisecond,
+		RetryMaxInterval:      time.Duration(c.RetryMaxInterval) * time.Millisecond,
+		RetryMaxElapsedTime:   time.Duration(c.RetryMaxElapsedTime) * time.Millisecond,
+		Exporters:             c.TracesExporters,
+		ConsoleWriter:         c.consoleWriter,
+		FileWriter:            sdk.fileWriter,
+		QueueDir:              c.TracesQueueDir,
+		QueueMaxBytes:         c.TracesQueueMaxBytes,
+	}, nil
 }
 
-func setupMetrics(c *Config) (func() error, error) {
-	endpoint, insecure := c.getMetricsEndpoint()
+func setupMetrics(c *Config, sdk *SDK) error {
 	var enabled bool
 	if c.MetricsEnabled == nil {
 		enabled = true
@@ -616,79 +1210,209 @@ func setupMetrics(c *Config) (func() error, error) {
 	}
 	if !enabled {
 		c.Logger.Debugf("metrics are disabled by configuration: enabled set to false")
-		return nil, nil
+		return nil
 	}
-	if endpoint == "" {
-		c.Logger.Debugf("metrics are disabled by configuration: no endpoint set")
-		return nil, nil
+	if sendsToOTLP(c.MetricsExporters) {
+		endpoint, err := c.getMetricsEndpoint()
+		if err != nil {
+			return err
+		}
+		if endpoint.Host == "" {
+			c.Logger.Debugf("metrics are disabled by configuration: no endpoint set")
+			return nil
+		}
 	}
 
-	return pipelines.NewMetricsPipeline(pipelines.PipelineConfig{
-		Protocol:        pipelines.Protocol(c.MetricsExporterProtocol),
-		Endpoint:        trimHttpScheme(endpoint, c.MetricsExporterProtocol),
-		Insecure:        insecure,
-		Headers:         c.getMetricsHeaders(),
-		Resource:        c.Resource,
-		ReportingPeriod: c.MetricsReportingPeriod,
-	})
-}
-
-// ConfigureOpenTelemetry is a function that be called with zero or more options.
-// Options can be the basic ones above, or provided by individual vendors.
-func ConfigureOpenTelemetry(opts ...Option) (func(), error) {
-	c, err := newConfig(opts...)
+	pipelineConfig, err := metricsPipelineConfig(c, sdk)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	if c.LogLevel == "debug" {
-		c.Logger.Debugf("debug logging enabled")
-		c.Logger.Debugf("configuration")
-		s, _ := json.MarshalIndent(c, "", "\t")
-		c.Logger.Debugf(string(s))
-	}
+	sdk.metricsReloader = &pipelines.Reloader{}
+	pipelineConfig.Reloader = sdk.metricsReloader
+	sdk.MeterProvider, err = pipelines.NewMeterProvider(pipelineConfig)
+	return err
+}
 
-	// Give a vendor a chance to validate the configuration
-	if ValidateConfig != nil {
-		if err := ValidateConfig(c); err != nil {
-			return nil, err
+// metricsPipelineConfig converts c into the configuration of the metrics pipeline.
+func metricsPipelineConfig(c *Config, sdk *SDK) (pipelines.PipelineConfig, error) {
+	var endpoint exporterEndpoint
+	var tlsConfig *tls.Config
+	compression := signalSetting("METRICS", "COMPRESSION", c.MetricsExporterCompression, c.ExporterCompression)
+	if sendsToOTLP(c.MetricsExporters) {
+		var err error
+		if endpoint, err = c.getMetricsEndpoint(); err != nil {
+			return pipelines.PipelineConfig{}, err
+		}
+		if tlsConfig, err = c.getMetricsTLSConfig(); err != nil {
+			return pipelines.PipelineConfig{}, err
+		}
+		if err = pipelines.CheckCompression(pipelines.Protocol(c.MetricsExporterProtocol), compression.value); err != nil {
+			return pipelines.PipelineConfig{}, compression.invalid(err)
 		}
 	}
 
-	if c.errorHandler != nil {
-		otel.SetErrorHandler(c.errorHandler)
+	return pipelines.PipelineConfig{
+		Protocol:             pipelines.Protocol(c.MetricsExporterProtocol),
+		Endpoint:             endpoint.Host,
+		Socket:               endpoint.Socket,
+		URLPath:              endpoint.Path,
+		Insecure:             endpoint.Insecure,
+		Headers:              c.getMetricsHeaders(),
+		Resource:             c.Resource,
+		ReportingPeriod:      c.MetricsReportingPeriod,
+		TLSConfig:            tlsConfig,
+		HTTPClient:           firstNonNil(c.metricsHTTPClient, c.httpClient),
+		HTTPTransport:        firstNonNilTransport(c.metricsHTTPTransport, c.httpTransport),
+		GRPCDialOptions:      c.grpcDialOptions,
+		GRPCConn:             c.grpcConn,
+		HeaderProvider:       pipelines.HeaderProvider(c.headerProvider),
+		Compression:          compression.value,
+		Timeout:              c.exportTimeout(c.MetricsExporterTimeout),
+		RetryDisabled:        c.retryDisabled(),
+		RetryInitialInterval: time.Duration(c.RetryInitialInterval) * time.Millisecond,
+		RetryMaxInterval:     time.Duration(c.RetryMaxInterval) * time.Millisecond,
+		RetryMaxElapsedTime:  time.Duration(c.RetryMaxElapsedTime) * time.Millisecond,
+		Exporters:            c.MetricsExporters,
+		ConsoleWriter:        c.consoleWriter,
+		FileWriter:           sdk.fileWriter,
+		PrometheusHost:       c.PrometheusHost,
+		PrometheusPort:       c.PrometheusPort,
+		Views:                c.MetricViews,
+	}, nil
+}
+
+func setupLogs(c *Config, sdk *SDK) error {
+	// unlike traces and metrics, logs are off unless they are enabled
+	enabled := c.LogsEnabled != nil && *c.LogsEnabled
+	if !enabled {
+		c.Logger.Debugf("logs are disabled by configuration: enabled set to false")
+		return nil
+	}
+	if sendsToOTLP(c.LogsExporters) {
+		endpoint, err := c.getLogsEndpoint()
+		if err != nil {
+			return err
+		}
+		if endpoint.Host == "" {
+			c.Logger.Debugf("logs are disabled by configuration: no endpoint set")
+			return nil
+		}
 	}
 
-	otelConfig := OtelConfig{
-		config: c,
+	pipelineConfig, err := logsPipelineConfig(c, sdk)
+	if err != nil {
+		return err
 	}
 
-	for _, setup := range []setupFunc{setupTracing, setupMetrics} {
-		shutdown, err := setup(c)
-		if err != nil {
-			return otelConfig.Shutdown, fmt.Errorf("setup error: %w", err)
+	sdk.logsReloader = &pipelines.Reloader{}
+	pipelineConfig.Reloader = sdk.logsReloader
+	sdk.LoggerProvider, err = pipelines.NewLoggerProvider(pipelineConfig)
+	return err
+}
+
+// logsPipelineConfig converts c into the configuration of the logs pipeline.
+func logsPipelineConfig(c *Config, sdk *SDK) (pipelines.PipelineConfig, error) {
+	var endpoint exporterEndpoint
+	var tlsConfig *tls.Config
+	compression := signalSetting("LOGS", "COMPRESSION", c.LogsExporterCompression, c.ExporterCompression)
+	if sendsToOTLP(c.LogsExporters) {
+		var err error
+		if endpoint, err = c.getLogsEndpoint(); err != nil {
+			return pipelines.PipelineConfig{}, err
+		}
+		if tlsConfig, err = c.getLogsTLSConfig(); err != nil {
+			return pipelines.PipelineConfig{}, err
 		}
-		if shutdown != nil {
-			otelConfig.shutdownFuncs = append(otelConfig.shutdownFuncs, shutdown)
+		if err = pipelines.CheckCompression(pipelines.Protocol(c.LogsExporterProtocol), compression.value); err != nil {
+			return pipelines.PipelineConfig{}, compression.invalid(err)
 		}
 	}
-	return otelConfig.Shutdown, nil
+
+	return pipelines.PipelineConfig{
+		Protocol:             pipelines.Protocol(c.LogsExporterProtocol),
+		Endpoint:             endpoint.Host,
+		Socket:               endpoint.Socket,
+		URLPath:              endpoint.Path,
+		Insecure:             endpoint.Insecure,
+		Headers:              c.getLogsHeaders(),
+		Resource:             c.Resource,
+		TLSConfig:            tlsConfig,
+		HTTPClient:           firstNonNil(c.logsHTTPClient, c.httpClient),
+		HTTPTransport:        firstNonNilTransport(c.logsHTTPTransport, c.httpTransport),
+		GRPCDialOptions:      c.grpcDialOptions,
+		GRPCConn:             c.grpcConn,
+		HeaderProvider:       pipelines.HeaderProvider(c.headerProvider),
+		Compression:          compression.value,
+		Timeout:              c.exportTimeout(c.LogsExporterTimeout),
+		RetryDisabled:        c.retryDisabled(),
+		RetryInitialInterval: time.Duration(c.RetryInitialInterval) * time.Millisecond,
+		RetryMaxInterval:     time.Duration(c.RetryMaxInterval) * time.Millisecond,
+		RetryMaxElapsedTime:  time.Duration(c.RetryMaxElapsedTime) * time.Millisecond,
+		Exporters:            c.LogsExporters,
+		ConsoleWriter:        c.consoleWriter,
+		FileWriter:           sdk.fileWriter,
+	}, nil
+}
+
+// ConfigureOpenTelemetry is a function that be called with zero or more options.
+// Options can be the basic ones above, or provided by individual vendors.
+func ConfigureOpenTelemetry(opts ...Option) (func(), error) {
+	otelConfig, err := Configure(opts...)
+	if otelConfig == nil {
+		return nil, err
+	}
+	return otelConfig.Shutdown, err
+}
+
+// Configure sets up OpenTelemetry in the same way as ConfigureOpenTelemetry, but returns
+// the OtelConfig itself so that callers can flush or shut it down with a context.
+// If one of the pipelines fails to start, the OtelConfig is returned along with the error
+// so that the pipelines that did start can still be shut down.
+func Configure(opts ...Option) (*OtelConfig, error) {
+	sdk, err := NewSDK(opts...)
+	if sdk == nil {
+		return nil, err
+	}
+
+	if sdk.Config.errorHandler != nil {
+		otel.SetErrorHandler(sdk.Config.errorHandler)
+	}
+	sdk.RegisterGlobals()
+	if sdk.Config.SlogDefault && sdk.LoggerProvider != nil {
+		slog.SetDefault(slog.New(NewSlogHandler()))
+	}
+	return &OtelConfig{sdk: sdk}, err
 }
 
 // Shutdown is the function called to shut down OpenTelemetry. It invokes any registered
-// shutdown functions.
+// shutdown functions, and calls Logger.Fatalf if any of them fail.
+// Use ShutdownContext to bound the time spent shutting down and handle errors yourself.
 func (ls OtelConfig) Shutdown() {
-	// call config shutdown functions first
-	for _, shutdown := range ls.config.ShutdownFunctions {
-		err := shutdown(ls.config)
-		if err != nil {
-			ls.config.Logger.Fatalf("failed to stop exporter while calling config shutdown: %v", err)
-		}
+	if err := ls.ShutdownContext(context.Background()); err != nil {
+		ls.sdk.Config.Logger.Fatalf("%v", err)
 	}
+}
 
-	for _, shutdown := range ls.shutdownFuncs {
-		if err := shutdown(); err != nil {
-			ls.config.Logger.Fatalf("failed to stop exporter: %v", err)
-		}
-	}
+// ShutdownContext shuts down OpenTelemetry, flushing any buffered telemetry. The config
+// shutdown functions are called first, followed by the traces, metrics and logs pipelines.
+// Every function is called even if an earlier one fails, and the failures are returned
+// together as a single error. Pipelines stop waiting for their final export once ctx is done.
+func (ls OtelConfig) ShutdownContext(ctx context.Context) error {
+	return ls.sdk.Shutdown(ctx)
+}
+
+// ForceFlush exports any spans, metrics and log records that are buffered by the pipelines,
+// without shutting them down. It is useful at the end of short-lived work, such as a Lambda
+// invocation, where buffered telemetry would otherwise be lost if the process is frozen.
+// Every pipeline is flushed even if an earlier one fails, and the failures are returned
+// together as a single error.
+func (ls OtelConfig) ForceFlush(ctx context.Context) error {
+	return ls.sdk.ForceFlush(ctx)
+}
+
+// Reload resolves the configuration again and applies the sampler, log level and exporter
+// settings to the running pipelines. See SDK.Reload for the details.
+func (ls OtelConfig) Reload() error {
+	return ls.sdk.Reload()
 }
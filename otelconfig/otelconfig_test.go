@@ -1,29 +1,50 @@
 package otelconfig
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"io"
+	"math/big"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/honeycombio/otel-config-go/otelconfig/pipelines"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
 
 	"go.opentelemetry.io/contrib/detectors/aws/lambda"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/baggage"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	collectorlogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
 	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
 	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
 )
@@ -35,6 +56,8 @@ const (
 	expectedMetricsDisabledMessage       = "metrics are disabled by configuration: no endpoint set"
 	expectedTracingDisabledConfigMessage = "tracing is disabled by configuration: enabled set to false"
 	expectedMetricsDisabledConfigMessage = "metrics are disabled by configuration: enabled set to false"
+	expectedLogsDisabledMessage          = "logs are disabled by configuration: no endpoint set"
+	expectedLogsDisabledConfigMessage    = "logs are disabled by configuration: enabled set to false"
 )
 
 type testLogger struct {
@@ -82,15 +105,25 @@ var falseVal = false
 type dummyTraceServer struct {
 	collectortrace.UnimplementedTraceServiceServer
 
+	mu                                 sync.Mutex
 	recievedExportTraceServiceRequests []*collectortrace.ExportTraceServiceRequest
 }
 
 func (s *dummyTraceServer) Export(ctx context.Context, req *collectortrace.ExportTraceServiceRequest) (*collectortrace.ExportTraceServiceResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.recievedExportTraceServiceRequests = append(s.recievedExportTraceServiceRequests, req)
 
 	return &collectortrace.ExportTraceServiceResponse{}, nil
 }
 
+// requests returns the export requests received so far.
+func (s *dummyTraceServer) requests() []*collectortrace.ExportTraceServiceRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*collectortrace.ExportTraceServiceRequest(nil), s.recievedExportTraceServiceRequests...)
+}
+
 type dummyMetricsServer struct {
 	collectormetrics.UnimplementedMetricsServiceServer
 }
@@ -99,6 +132,14 @@ func (*dummyMetricsServer) Export(ctx context.Context, req *collectormetrics.Exp
 	return &collectormetrics.ExportMetricsServiceResponse{}, nil
 }
 
+type dummyLogsServer struct {
+	collectorlogs.UnimplementedLogsServiceServer
+}
+
+func (*dummyLogsServer) Export(ctx context.Context, req *collectorlogs.ExportLogsServiceRequest) (*collectorlogs.ExportLogsServiceResponse, error) {
+	return &collectorlogs.ExportLogsServiceResponse{}, nil
+}
+
 // dummyGRPCListener is a test helper that builds a dummy grpc server that does nothing but
 // returns quickly so that we don't have to wait for timeouts.
 func dummyGRPCListener() func() {
@@ -109,6 +150,7 @@ func dummyGRPCListenerWithTraceServer(traceServer collectortrace.TraceServiceSer
 	grpcServer := grpc.NewServer()
 	collectortrace.RegisterTraceServiceServer(grpcServer, traceServer)
 	collectormetrics.RegisterMetricsServiceServer(grpcServer, &dummyMetricsServer{})
+	collectorlogs.RegisterLogsServiceServer(grpcServer, &dummyLogsServer{})
 
 	// we listen on localhost, not 0.0.0.0, because otherwise firewalls can get upset
 	// and get in the way of testing.
@@ -120,7 +162,12 @@ func dummyGRPCListenerWithTraceServer(traceServer collectortrace.TraceServiceSer
 	go func() {
 		_ = grpcServer.Serve(l)
 	}()
-	return grpcServer.Stop
+	return func() {
+		grpcServer.Stop()
+		// Stop only closes listeners that Serve has already picked up, so close
+		// ours too in case the server goroutine hasn't started yet.
+		_ = l.Close()
+	}
 }
 
 // withTestExporters conforms to the Option interface and sets up the options needed
@@ -136,6 +183,8 @@ func withTestExporters() Option {
 		WithTracesExporterInsecure(true)(c)
 		WithMetricsExporterEndpoint("localhost:4317")(c)
 		WithMetricsExporterInsecure(true)(c)
+		WithLogsExporterEndpoint("localhost:4317")(c)
+		WithLogsExporterInsecure(true)(c)
 	}
 }
 
@@ -202,6 +251,16 @@ func testSignalDisabled(t *testing.T, expected string, opts ...Option) {
 	logger.requireContains(t, expected)
 }
 
+func TestLogEndpointDisabled(t *testing.T) {
+	testEndpointDisabled(
+		t,
+		expectedLogsDisabledMessage,
+		WithLogsEnabled(true),
+		WithLogsExporterEndpoint(""),
+		WithExporterEndpoint(""),
+	)
+}
+
 func TestMetricsDisabled(t *testing.T) {
 	testSignalDisabled(
 		t,
@@ -218,6 +277,14 @@ func TestTracesDisabled(t *testing.T) {
 	)
 }
 
+func TestLogsDisabled(t *testing.T) {
+	testSignalDisabled(
+		t,
+		expectedLogsDisabledConfigMessage,
+		WithLogsEnabled(false),
+	)
+}
+
 func TestValidConfig(t *testing.T) {
 	logger := &testLogger{}
 
@@ -229,6 +296,7 @@ func TestValidConfig(t *testing.T) {
 	defer stopper()
 
 	shutdown, err := ConfigureOpenTelemetry(
+		WithLogsEnabled(true),
 		WithLogger(logger),
 		WithServiceName("test-service"),
 		withTestExporters(),
@@ -251,8 +319,11 @@ func TestInvalidEnvironment(t *testing.T) {
 		WithLogger(logger),
 		WithServiceName("test-service"),
 	)
-	require.ErrorContains(t, err, "environment error")
-	logger.requireContains(t, "environment error")
+	var configErr *ConfigError
+	require.ErrorAs(t, err, &configErr)
+	assert.Equal(t, "OTEL_EXPORTER_OTLP_METRICS_INSECURE", configErr.Variable)
+	assert.Equal(t, "bleargh", configErr.Value)
+	assert.Empty(t, logger.output)
 }
 
 func TestInvalidMetricsPushIntervalEnv(t *testing.T) {
@@ -336,15 +407,30 @@ func TestDefaultConfig(t *testing.T) {
 		MetricsExporterEndpointInsecure: false,
 		MetricsEnabled:                  &trueVal,
 		MetricsReportingPeriod:          "30s",
+		LogsExporterEndpoint:            "",
+		LogsExporterEndpointInsecure:    false,
+		LogsEnabled:                     &falseVal,
 		LogLevel:                        "info",
 		Headers:                         map[string]string{},
 		TracesHeaders:                   map[string]string{},
 		MetricsHeaders:                  map[string]string{},
+		LogsHeaders:                     map[string]string{},
 		ResourceAttributes:              map[string]string{},
 		Propagators:                     []string{"tracecontext", "baggage"},
 		Resource:                        resource.NewWithAttributes(semconv.SchemaURL, attributes...),
 		Logger:                          logger,
 		ExporterProtocol:                "grpc",
+		ExporterCompression:             "gzip",
+		ExporterTimeout:                 10000,
+		RetryEnabled:                    &trueVal,
+		TracesExporters:                 []string{"otlp"},
+		MetricsExporters:                []string{"otlp"},
+		LogsExporters:                   []string{"otlp"},
+		PrometheusHost:                  "localhost",
+		PrometheusPort:                  9464,
+		FileExporterMaxBytes:            104857600,
+		FileExporterMaxBackups:          3,
+		TracesQueueMaxBytes:             104857600,
 		errorHandler:                    handler,
 		Sampler:                         trace.AlwaysSample(),
 	}
@@ -421,6 +507,25 @@ func TestEnvironmentVariables(t *testing.T) {
 		MetricsExporterProtocol:         Protocol(environmentOtelSettings["OTEL_EXPORTER_OTLP_METRICS_PROTOCOL"]),
 		MetricsHeaders:                  map[string]string{"env-metrics-headers": "present", "header-clobber": "ENV_WON"},
 		MetricsReportingPeriod:          environmentOtelSettings["OTEL_EXPORTER_OTLP_METRICS_PERIOD"],
+		LogsEnabled:                     &falseVal,
+		LogsExporterEndpoint:            environmentOtelSettings["OTEL_EXPORTER_OTLP_LOGS_ENDPOINT"],
+		LogsExporterEndpointInsecure:    true,
+		LogsExporterProtocol:            Protocol(environmentOtelSettings["OTEL_EXPORTER_OTLP_LOGS_PROTOCOL"]),
+		LogsHeaders:                     map[string]string{"env-logs-headers": "present", "header-clobber": "ENV_WON"},
+		ExporterCompression:             environmentOtelSettings["OTEL_EXPORTER_OTLP_COMPRESSION"],
+		LogsExporterCompression:         environmentOtelSettings["OTEL_EXPORTER_OTLP_LOGS_COMPRESSION"],
+		ExporterTimeout:                 5000,
+		MetricsExporterTimeout:          2000,
+		RetryEnabled:                    &trueVal,
+		RetryInitialInterval:            500,
+		TracesExporters:                 []string{"otlp", "console"},
+		MetricsExporters:                []string{"otlp"},
+		LogsExporters:                   []string{"otlp"},
+		PrometheusHost:                  "localhost",
+		PrometheusPort:                  9465,
+		FileExporterMaxBytes:            104857600,
+		FileExporterMaxBackups:          3,
+		TracesQueueMaxBytes:             104857600,
 		Sampler:                         trace.AlwaysSample(),
 		errorHandler:                    handler,
 	}
@@ -457,6 +562,9 @@ func TestConfigurationOverrides(t *testing.T) {
 		WithHeaders(map[string]string{"code-headers": "present", "header-clobber": "CODE_WON"}),
 		WithTracesHeaders(map[string]string{"code-traces": "present", "header-clobber": "CODE_WON"}),
 		WithMetricsHeaders(map[string]string{"code-metrics": "present", "header-clobber": "CODE_WON"}),
+		WithLogsExporterEndpoint("logs-endpoint-from-code"),
+		WithLogsExporterInsecure(false),
+		WithLogsHeaders(map[string]string{"code-logs": "present", "header-clobber": "CODE_WON"}),
 		WithLogLevel("info"),
 		WithLogger(logger),
 		WithErrorHandler(handler),
@@ -464,6 +572,16 @@ func TestConfigurationOverrides(t *testing.T) {
 		WithExporterProtocol("http/json"),
 		WithMetricsExporterProtocol("http/json"),
 		WithTracesExporterProtocol("http/json"),
+		WithLogsExporterProtocol("http/json"),
+		WithExporterCompression("gzip"),
+		WithTracesExporterCompression("zstd"),
+		WithLogsExporterCompression("none"),
+		WithExporterTimeout(time.Second),
+		WithTracesExporterTimeout(3*time.Second),
+		WithRetryEnabled(false),
+		WithRetryInitialInterval(time.Second),
+		WithRetryMaxInterval(10*time.Second),
+		WithMetricsExporters([]string{"console"}),
 		WithResourceOption(resource.WithAttributes(
 			attribute.String("a.code.attr", "hey"),
 			attribute.String("resource.clobber", "CODE_WON"),
@@ -525,18 +643,42 @@ func TestConfigurationOverrides(t *testing.T) {
 		MetricsExporterProtocol:         Protocol(environmentOtelSettings["OTEL_EXPORTER_OTLP_METRICS_PROTOCOL"]),
 		MetricsHeaders:                  map[string]string{"env-metrics-headers": "present", "header-clobber": "ENV_WON"},
 		MetricsReportingPeriod:          environmentOtelSettings["OTEL_EXPORTER_OTLP_METRICS_PERIOD"],
+		LogsEnabled:                     &falseVal,
+		LogsExporterEndpoint:            environmentOtelSettings["OTEL_EXPORTER_OTLP_LOGS_ENDPOINT"],
+		LogsExporterEndpointInsecure:    true,
+		LogsExporterProtocol:            Protocol(environmentOtelSettings["OTEL_EXPORTER_OTLP_LOGS_PROTOCOL"]),
+		LogsHeaders:                     map[string]string{"env-logs-headers": "present", "header-clobber": "ENV_WON"},
+		ExporterCompression:             environmentOtelSettings["OTEL_EXPORTER_OTLP_COMPRESSION"],
+		TracesExporterCompression:       "zstd",
+		LogsExporterCompression:         environmentOtelSettings["OTEL_EXPORTER_OTLP_LOGS_COMPRESSION"],
+		ExporterTimeout:                 5000,
+		TracesExporterTimeout:           3000,
+		MetricsExporterTimeout:          2000,
+		RetryEnabled:                    &falseVal,
+		RetryInitialInterval:            500,
+		RetryMaxInterval:                10000,
+		TracesExporters:                 []string{"otlp", "console"},
+		MetricsExporters:                []string{"console"},
+		LogsExporters:                   []string{"otlp"},
+		PrometheusHost:                  "localhost",
+		PrometheusPort:                  9465,
+		FileExporterMaxBytes:            104857600,
+		FileExporterMaxBackups:          3,
+		TracesQueueMaxBytes:             104857600,
 		Sampler:                         trace.AlwaysSample(),
 		errorHandler:                    handler,
 	}
 	// Generic and signal-specific headers should merge
 	expectedTraceHeaders := map[string]string{"env-headers": "present", "env-traces-headers": "present", "header-clobber": "ENV_WON"}
 	expectedMetricsHeaders := map[string]string{"env-headers": "present", "env-metrics-headers": "present", "header-clobber": "ENV_WON"}
+	expectedLogsHeaders := map[string]string{"env-headers": "present", "env-logs-headers": "present", "header-clobber": "ENV_WON"}
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedConfiguredResource, testConfig.Resource)
 	assert.Equal(t, expectedConfig, testConfig)
 	assert.Equal(t, expectedTraceHeaders, testConfig.getTracesHeaders())
 	assert.Equal(t, expectedMetricsHeaders, testConfig.getMetricsHeaders())
+	assert.Equal(t, expectedLogsHeaders, testConfig.getLogsHeaders())
 }
 
 type TestCarrier struct {
@@ -898,6 +1040,811 @@ func TestConfigWithUnmergableResources(t *testing.T) {
 	assert.Equal(t, "", cfg.Resource.SchemaURL())
 }
 
+func TestShutdownContextRunsEveryShutdownFunction(t *testing.T) {
+	stopper := dummyGRPCListener()
+	defer stopper()
+
+	logger := &testLogger{}
+	var calls int
+	otelConfig, err := Configure(
+		WithLogger(logger),
+		WithShutdown(func(c *Config) error {
+			calls++
+			return errors.New("first shutdown failed")
+		}),
+		WithShutdown(func(c *Config) error {
+			calls++
+			return errors.New("second shutdown failed")
+		}),
+		withTestExporters(),
+	)
+	require.NoError(t, err)
+
+	err = otelConfig.ShutdownContext(context.Background())
+	assert.Equal(t, 2, calls)
+	assert.ErrorContains(t, err, "first shutdown failed")
+	assert.ErrorContains(t, err, "second shutdown failed")
+	logger.requireNotContains(t, "shutdown failed")
+}
+
+// unresponsiveCollector returns the address of a collector that accepts connections
+// but never answers.
+func unresponsiveCollector(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+	go func() {
+		var conns []net.Conn
+		defer func() {
+			for _, conn := range conns {
+				_ = conn.Close()
+			}
+		}()
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conns = append(conns, conn)
+		}
+	}()
+	return l.Addr().String()
+}
+
+func TestShutdownContextRespectsDeadline(t *testing.T) {
+	endpoint := unresponsiveCollector(t)
+
+	otelConfig, err := Configure(
+		WithLogger(&testLogger{}),
+		WithTracesExporterEndpoint(endpoint),
+		WithTracesExporterInsecure(true),
+		WithMetricsEnabled(false),
+		WithLogsEnabled(false),
+	)
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "stuck")
+	span.End()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = otelConfig.ShutdownContext(ctx)
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestPipelineSetupFuncs(t *testing.T) {
+	c := pipelines.PipelineConfig{
+		Resource:      resource.Empty(),
+		Sampler:       trace.AlwaysSample(),
+		Propagators:   []string{"tracecontext"},
+		Exporters:     []string{pipelines.ExporterConsole},
+		ConsoleWriter: io.Discard,
+	}
+
+	for _, setup := range []pipelines.PipelineSetupFunc{
+		pipelines.NewTracePipeline,
+		pipelines.NewMetricsPipeline,
+		pipelines.NewLogsPipeline,
+	} {
+		shutdown, err := setup(c)
+		require.NoError(t, err)
+		assert.NoError(t, shutdown())
+	}
+
+	for _, setup := range []pipelines.PipelineSetupWithContextFunc{
+		pipelines.NewTracePipelineWithContext,
+		pipelines.NewMetricsPipelineWithContext,
+		pipelines.NewLogsPipelineWithContext,
+	} {
+		shutdown, err := setup(c)
+		require.NoError(t, err)
+		assert.NoError(t, shutdown(context.Background()))
+	}
+}
+
+func TestExporterTimeout(t *testing.T) {
+	endpoint := unresponsiveCollector(t)
+
+	otelConfig, err := Configure(
+		WithLogger(&testLogger{}),
+		WithTracesExporterEndpoint(endpoint),
+		WithTracesExporterInsecure(true),
+		WithTracesExporterTimeout(100*time.Millisecond),
+		WithMetricsEnabled(false),
+		WithLogsEnabled(false),
+	)
+	require.NoError(t, err)
+	defer func() { _ = otelConfig.ShutdownContext(context.Background()) }()
+
+	_, span := otel.Tracer("test").Start(context.Background(), "stuck")
+	span.End()
+
+	start := time.Now()
+	err = otelConfig.ForceFlush(context.Background())
+	assert.ErrorContains(t, err, "deadline exceeded")
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestRetryDisabled(t *testing.T) {
+	endpoint := unresponsiveCollector(t)
+
+	// the HTTP exporters retry timed out requests until the retry policy gives up
+	otelConfig, err := Configure(
+		WithLogger(&testLogger{}),
+		WithTracesExporterEndpoint(endpoint),
+		WithTracesExporterInsecure(true),
+		WithTracesExporterProtocol("http/protobuf"),
+		WithTracesExporterTimeout(100*time.Millisecond),
+		WithRetryEnabled(false),
+		WithMetricsEnabled(false),
+		WithLogsEnabled(false),
+	)
+	require.NoError(t, err)
+	defer func() { _ = otelConfig.ShutdownContext(context.Background()) }()
+
+	_, span := otel.Tracer("test").Start(context.Background(), "stuck")
+	span.End()
+
+	start := time.Now()
+	assert.Error(t, otelConfig.ForceFlush(context.Background()))
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestHttpJSONRetry(t *testing.T) {
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	otelConfig, err := Configure(
+		WithLogger(&testLogger{}),
+		WithTracesExporterEndpoint(ts.URL),
+		WithTracesExporterInsecure(true),
+		WithTracesExporterProtocol("http/json"),
+		WithRetryInitialInterval(10*time.Millisecond),
+		WithRetryMaxElapsedTime(5*time.Second),
+		WithMetricsEnabled(false),
+		WithLogsEnabled(false),
+	)
+	require.NoError(t, err)
+	defer func() { _ = otelConfig.ShutdownContext(context.Background()) }()
+
+	_, span := otel.Tracer("test").Start(context.Background(), "retried")
+	span.End()
+	require.NoError(t, otelConfig.ForceFlush(context.Background()))
+	assert.Equal(t, int32(3), requests.Load())
+
+	// requests aren't retried when retries are disabled
+	requests.Store(0)
+	otelConfig, err = Configure(
+		WithLogger(&testLogger{}),
+		WithTracesExporterEndpoint(ts.URL),
+		WithTracesExporterInsecure(true),
+		WithTracesExporterProtocol("http/json"),
+		WithRetryEnabled(false),
+		WithMetricsEnabled(false),
+		WithLogsEnabled(false),
+	)
+	require.NoError(t, err)
+	defer func() { _ = otelConfig.ShutdownContext(context.Background()) }()
+
+	_, span = otel.Tracer("test").Start(context.Background(), "dropped")
+	span.End()
+	assert.ErrorContains(t, otelConfig.ForceFlush(context.Background()), "503 Service Unavailable")
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestHttpJSONRetryHonorsRetryAfter(t *testing.T) {
+	var mu sync.Mutex
+	var received []time.Time
+	retryAfter := "1"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, time.Now())
+		if len(received) == 1 {
+			w.Header().Set("Retry-After", retryAfter)
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer ts.Close()
+
+	configure := func(maxElapsed time.Duration) *OtelConfig {
+		otelConfig, err := Configure(
+			WithLogger(&testLogger{}),
+			WithTracesExporterEndpoint(ts.URL),
+			WithTracesExporterInsecure(true),
+			WithTracesExporterProtocol("http/json"),
+			WithRetryInitialInterval(10*time.Millisecond),
+			WithRetryMaxElapsedTime(maxElapsed),
+			WithMetricsEnabled(false),
+			WithLogsEnabled(false),
+		)
+		require.NoError(t, err)
+		return otelConfig
+	}
+
+	otelConfig := configure(5 * time.Second)
+	defer func() { _ = otelConfig.ShutdownContext(context.Background()) }()
+	_, span := otel.Tracer("test").Start(context.Background(), "throttled")
+	span.End()
+	require.NoError(t, otelConfig.ForceFlush(context.Background()))
+	mu.Lock()
+	require.Len(t, received, 2)
+	assert.GreaterOrEqual(t, received[1].Sub(received[0]), time.Second, "the retry waits as long as Retry-After asks")
+
+	// a Retry-After beyond the max elapsed time gives up rather than waiting for it
+	received = nil
+	retryAfter = "3600"
+	mu.Unlock()
+	otelConfig = configure(2 * time.Second)
+	defer func() { _ = otelConfig.ShutdownContext(context.Background()) }()
+	_, span = otel.Tracer("test").Start(context.Background(), "dropped")
+	span.End()
+	start := time.Now()
+	assert.ErrorContains(t, otelConfig.ForceFlush(context.Background()), "max retry time elapsed")
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+// syncBuffer is a bytes.Buffer that can be written to by several exporters at once.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestConsoleExporters(t *testing.T) {
+	var console syncBuffer
+	otelConfig, err := Configure(
+		WithLogsEnabled(true),
+		WithLogger(&testLogger{}),
+		WithTracesExporters([]string{ExporterConsole}),
+		WithMetricsExporters([]string{ExporterConsole}),
+		WithLogsExporters([]string{ExporterConsole}),
+		WithConsoleWriter(&console),
+	)
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "console-span")
+	span.End()
+	counter, err := otel.Meter("test").Int64Counter("console.counter")
+	require.NoError(t, err)
+	counter.Add(context.Background(), 1)
+	var record otellog.Record
+	record.SetBody(otellog.StringValue("console-log"))
+	global.GetLoggerProvider().Logger("test").Emit(context.Background(), record)
+
+	require.NoError(t, otelConfig.ShutdownContext(context.Background()))
+	output := console.String()
+	assert.Contains(t, output, `"Name": "console-span"`)
+	assert.Contains(t, output, `"Name": "console.counter"`)
+	assert.Contains(t, output, `"Value": "console-log"`)
+}
+
+func TestExportersFanOut(t *testing.T) {
+	traceServer := &dummyTraceServer{}
+	stopper := dummyGRPCListenerWithTraceServer(traceServer)
+	defer stopper()
+
+	var console syncBuffer
+	setenv("OTEL_TRACES_EXPORTER", "otlp, console")
+	defer unsetAllOtelEnvironmentVariables()
+	otelConfig, err := Configure(
+		WithLogger(&testLogger{}),
+		WithMetricsEnabled(false),
+		WithLogsEnabled(false),
+		WithConsoleWriter(&console),
+		withTestExporters(),
+	)
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "fan-out-span")
+	span.End()
+	require.NoError(t, otelConfig.ShutdownContext(context.Background()))
+	assert.Len(t, traceServer.requests(), 1)
+	assert.Contains(t, console.String(), `"Name": "fan-out-span"`)
+}
+
+func TestNoneExporter(t *testing.T) {
+	traceServer := &dummyTraceServer{}
+	stopper := dummyGRPCListenerWithTraceServer(traceServer)
+	defer stopper()
+
+	recorder := tracetest.NewSpanRecorder()
+	otelConfig, err := Configure(
+		WithLogger(&testLogger{}),
+		WithTracesExporters([]string{ExporterNone}),
+		WithSpanProcessor(recorder),
+		WithMetricsEnabled(false),
+		WithLogsEnabled(false),
+		withTestExporters(),
+	)
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "unexported-span")
+	span.End()
+	require.NoError(t, otelConfig.ShutdownContext(context.Background()))
+	assert.Empty(t, traceServer.requests())
+	assert.Len(t, recorder.Ended(), 1, "spans still reach other span processors")
+}
+
+func TestUnsupportedExporter(t *testing.T) {
+	shutdown, err := ConfigureOpenTelemetry(
+		WithLogger(&testLogger{}),
+		WithTracesExporters([]string{"zipkin"}),
+		withTestExporters(),
+	)
+	defer shutdown()
+	assert.ErrorContains(t, err, `unsupported traces exporter "zipkin"`)
+}
+
+func TestPrometheusExporter(t *testing.T) {
+	port := freePort(t)
+
+	otelConfig, err := Configure(
+		WithLogger(&testLogger{}),
+		WithMetricsExporters([]string{ExporterPrometheus}),
+		WithPrometheusPort(port),
+		WithTracesEnabled(false),
+		WithLogsEnabled(false),
+	)
+	require.NoError(t, err)
+
+	counter, err := otel.Meter("test").Int64Counter("scraped.counter")
+	require.NoError(t, err)
+	counter.Add(context.Background(), 3)
+
+	resp, err := http.Get(fmt.Sprintf("http://localhost:%d/metrics", port))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), "scraped_counter_total")
+	assert.Contains(t, string(body), "process_runtime_go_goroutines", "runtime metrics are included")
+
+	// shutting down stops serving metrics, freeing the port
+	require.NoError(t, otelConfig.ShutdownContext(context.Background()))
+	_, err = http.Get(fmt.Sprintf("http://localhost:%d/metrics", port))
+	assert.Error(t, err)
+}
+
+// freePort returns a port that is free to listen on.
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	require.NoError(t, l.Close())
+	return port
+}
+
+func TestPrometheusExporterIgnoresOTLPEndpoint(t *testing.T) {
+	port := freePort(t)
+	otelConfig, err := Configure(
+		WithLogger(&testLogger{}),
+		WithMetricsExporters([]string{ExporterPrometheus}),
+		WithMetricsExporterEndpoint("collector:otlp"),
+		WithPrometheusPort(port),
+		WithTracesEnabled(false),
+		WithLogsEnabled(false),
+	)
+	require.NoError(t, err)
+	defer otelConfig.Shutdown()
+
+	resp, err := http.Get(fmt.Sprintf("http://localhost:%d/metrics", port))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestPrometheusExporterStopsWhenSetupFails(t *testing.T) {
+	port := freePort(t)
+	shutdown, err := ConfigureOpenTelemetry(
+		WithLogger(&testLogger{}),
+		WithMetricsExporters([]string{ExporterPrometheus, "bogus"}),
+		WithPrometheusPort(port),
+		WithTracesEnabled(false),
+		WithLogsEnabled(false),
+	)
+	defer shutdown()
+	require.ErrorContains(t, err, `unsupported metrics exporter "bogus"`)
+
+	// the port is free again, as the reader already created was shut down
+	l, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", port))
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+}
+
+// grpcGoroutines counts the goroutines running gRPC code, such as those of a client
+// connection that hasn't been closed.
+func grpcGoroutines() int {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	count := 0
+	for _, stack := range strings.Split(string(buf), "\n\n") {
+		if strings.Contains(stack, "google.golang.org/grpc") {
+			count++
+		}
+	}
+	return count
+}
+
+func TestExportersStopWhenSetupFails(t *testing.T) {
+	for _, signal := range []string{"traces", "logs"} {
+		t.Run(signal, func(t *testing.T) {
+			before := grpcGoroutines()
+			shutdown, err := ConfigureOpenTelemetry(
+				WithLogger(&testLogger{}),
+				WithExporterEndpoint("localhost:4317"),
+				WithExporterInsecure(true),
+				WithTracesEnabled(signal == "traces"),
+				WithTracesExporters([]string{ExporterOTLP, "bogus"}),
+				WithMetricsEnabled(false),
+				WithLogsEnabled(signal == "logs"),
+				WithLogsExporters([]string{ExporterOTLP, "bogus"}),
+			)
+			defer shutdown()
+			require.ErrorContains(t, err, fmt.Sprintf("unsupported %s exporter \"bogus\"", signal))
+
+			// the OTLP exporter already created was shut down, closing its connection
+			assert.Eventually(t, func() bool {
+				return grpcGoroutines() <= before
+			}, 5*time.Second, 10*time.Millisecond)
+		})
+	}
+}
+
+// readJSONLines parses every line of an exporter file.
+func readJSONLines(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		var request map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &request), line)
+		lines = append(lines, request)
+	}
+	return lines
+}
+
+func TestFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "otel", "data.json")
+	otelConfig, err := Configure(
+		WithLogsEnabled(true),
+		WithLogger(&testLogger{}),
+		WithTracesExporters([]string{ExporterFile}),
+		WithMetricsExporters([]string{ExporterFile}),
+		WithLogsExporters([]string{ExporterFile}),
+		WithFileExporterPath(path),
+	)
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "file-span")
+	span.End()
+	counter, err := otel.Meter("test").Int64Counter("file.counter")
+	require.NoError(t, err)
+	counter.Add(context.Background(), 1)
+	var record otellog.Record
+	record.SetBody(otellog.StringValue("file-log"))
+	global.GetLoggerProvider().Logger("test").Emit(context.Background(), record)
+	require.NoError(t, otelConfig.ShutdownContext(context.Background()))
+
+	requests := map[string]string{}
+	for _, request := range readJSONLines(t, path) {
+		for signal := range request {
+			line, err := json.Marshal(request)
+			require.NoError(t, err)
+			requests[signal] += string(line)
+		}
+	}
+	require.Len(t, requests, 3)
+	assert.Contains(t, requests["resourceSpans"], `"name":"file-span"`)
+	assert.Regexp(t, `"traceId":"[0-9a-f]{32}"`, requests["resourceSpans"])
+	assert.Contains(t, requests["resourceMetrics"], `"name":"file.counter"`)
+	assert.Contains(t, requests["resourceLogs"], `"stringValue":"file-log"`)
+}
+
+func TestFileExporterRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	otelConfig, err := Configure(
+		WithLogger(&testLogger{}),
+		WithTracesExporters([]string{ExporterFile}),
+		WithFileExporterPath(path),
+		WithFileExporterMaxBytes(1024),
+		WithFileExporterMaxBackups(2),
+		WithMetricsEnabled(false),
+		WithLogsEnabled(false),
+	)
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		_, span := otel.Tracer("test").Start(context.Background(), fmt.Sprintf("span-%d", i))
+		span.End()
+		require.NoError(t, otelConfig.ForceFlush(context.Background()))
+	}
+	require.NoError(t, otelConfig.ShutdownContext(context.Background()))
+
+	for _, file := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(file)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, info.Size(), int64(1024))
+		assert.NotEmpty(t, readJSONLines(t, file))
+	}
+	assert.NoFileExists(t, path+".3")
+}
+
+func TestFileExporterKeepsWritingWhenRotationFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	// the backup can't be replaced, as it is a directory that isn't empty
+	require.NoError(t, os.MkdirAll(filepath.Join(path+".1", "keep"), 0o755))
+
+	otelConfig, err := Configure(
+		WithLogger(&testLogger{}),
+		WithTracesExporters([]string{ExporterFile}),
+		WithFileExporterPath(path),
+		WithFileExporterMaxBytes(1024),
+		WithFileExporterMaxBackups(1),
+		WithMetricsEnabled(false),
+		WithLogsEnabled(false),
+	)
+	require.NoError(t, err)
+
+	const spans = 10
+	for i := 0; i < spans; i++ {
+		_, span := otel.Tracer("test").Start(context.Background(), fmt.Sprintf("span-%d", i))
+		span.End()
+		require.NoError(t, otelConfig.ForceFlush(context.Background()))
+	}
+	require.NoError(t, otelConfig.ShutdownContext(context.Background()))
+
+	assert.Len(t, readJSONLines(t, path), spans)
+	assert.DirExists(t, path+".1")
+}
+
+func TestFileExporterRequiresPath(t *testing.T) {
+	shutdown, err := ConfigureOpenTelemetry(
+		WithLogger(&testLogger{}),
+		WithLogsExporters([]string{ExporterFile}),
+		withTestExporters(),
+	)
+	defer shutdown()
+	assert.ErrorContains(t, err, "the file exporter requires a path")
+}
+
+// queuedFiles returns the sizes of the batches spooled to a persistent queue, oldest first.
+func queuedFiles(t *testing.T, dir string) []int64 {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pb"))
+	require.NoError(t, err)
+	sizes := []int64{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		sizes = append(sizes, info.Size())
+	}
+	return sizes
+}
+
+func TestTracesPersistentQueue(t *testing.T) {
+	dir := t.TempDir()
+	configure := func(opts ...Option) *OtelConfig {
+		otelConfig, err := Configure(append([]Option{
+			WithLogger(&testLogger{}),
+			withTestExporters(),
+			WithTracesQueueDir(dir),
+			WithRetryInitialInterval(50 * time.Millisecond),
+			WithMetricsEnabled(false),
+			WithLogsEnabled(false),
+		}, opts...)...)
+		require.NoError(t, err)
+		return otelConfig
+	}
+	export := func(otelConfig *OtelConfig, name string) {
+		_, span := otel.Tracer("otelconfig-tests").Start(context.Background(), name)
+		span.End()
+		require.NoError(t, otelConfig.ForceFlush(context.Background()))
+	}
+
+	// while the collector is down, batches are spooled and survive a restart
+	otelConfig := configure()
+	export(otelConfig, "span-1")
+	export(otelConfig, "span-2")
+	export(otelConfig, "span-3")
+	require.NoError(t, otelConfig.ShutdownContext(context.Background()))
+	sizes := queuedFiles(t, dir)
+	require.Len(t, sizes, 3)
+
+	// the oldest batches are dropped to keep the queue within its size
+	otelConfig = configure(WithTracesQueueMaxBytes(2 * sizes[0]))
+	export(otelConfig, "span-4")
+	require.NoError(t, otelConfig.ShutdownContext(context.Background()))
+	require.Len(t, queuedFiles(t, dir), 2)
+
+	// once the collector is back, the queue is replayed in order
+	traceServer := &dummyTraceServer{}
+	stopper := dummyGRPCListenerWithTraceServer(traceServer)
+	defer stopper()
+	otelConfig = configure()
+	defer otelConfig.Shutdown()
+	require.Eventually(t, func() bool {
+		return len(queuedFiles(t, dir)) == 0
+	}, 10*time.Second, 50*time.Millisecond)
+
+	var names []string
+	for _, req := range traceServer.requests() {
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				for _, span := range ss.Spans {
+					names = append(names, span.Name)
+				}
+			}
+		}
+	}
+	assert.Equal(t, []string{"span-3", "span-4"}, names)
+}
+
+func TestTracesPersistentQueueDropsRejectedBatches(t *testing.T) {
+	const (
+		unavailable = iota
+		rejectOnce
+		accept
+		reject
+	)
+	var mode, accepted atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case mode.Load() == unavailable:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case mode.CompareAndSwap(rejectOnce, accept), mode.Load() == reject:
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			accepted.Add(1)
+		}
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	otelConfig, err := Configure(
+		WithLogger(&testLogger{}),
+		WithTracesExporterEndpoint(ts.URL),
+		WithExporterProtocol(ProtocolHTTPProto),
+		WithTracesQueueDir(dir),
+		WithRetryInitialInterval(50*time.Millisecond),
+		WithMetricsEnabled(false),
+		WithLogsEnabled(false),
+	)
+	require.NoError(t, err)
+	defer otelConfig.Shutdown()
+
+	// batches are spooled while the collector is unavailable
+	exportSpans(t, otelConfig, 2)
+	require.Len(t, queuedFiles(t, dir), 2)
+
+	// a spooled batch the collector rejects is dropped, and the next one is still sent
+	mode.Store(rejectOnce)
+	require.Eventually(t, func() bool {
+		return len(queuedFiles(t, dir)) == 0
+	}, 10*time.Second, 50*time.Millisecond)
+	assert.Equal(t, int32(1), accepted.Load())
+
+	// batches the collector rejects are not spooled at all
+	mode.Store(reject)
+	_, span := otel.Tracer("otelconfig-tests").Start(context.Background(), "rejected-span")
+	span.End()
+	assert.ErrorContains(t, otelConfig.ForceFlush(context.Background()), "400 Bad Request")
+	assert.Empty(t, queuedFiles(t, dir))
+}
+
+func TestTracesPersistentQueueReplayDoesNotBlockExports(t *testing.T) {
+	var hang atomic.Bool
+	arrived, release := make(chan struct{}, 1), make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !hang.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		select {
+		case arrived <- struct{}{}:
+		default:
+		}
+		<-release
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	otelConfig, err := Configure(
+		WithLogger(&testLogger{}),
+		WithTracesExporterEndpoint(ts.URL),
+		WithExporterProtocol(ProtocolHTTPProto),
+		WithTracesQueueDir(dir),
+		WithRetryInitialInterval(50*time.Millisecond),
+		WithMetricsEnabled(false),
+		WithLogsEnabled(false),
+	)
+	require.NoError(t, err)
+	defer otelConfig.Shutdown()
+
+	exportSpans(t, otelConfig, 1)
+	require.Len(t, queuedFiles(t, dir), 1)
+
+	// while replaying the spooled batch hangs, new batches are spooled behind it
+	hang.Store(true)
+	<-arrived
+	_, span := otel.Tracer("otelconfig-tests").Start(context.Background(), "during-replay")
+	span.End()
+	flushed := make(chan error, 1)
+	go func() {
+		flushed <- otelConfig.ForceFlush(context.Background())
+	}()
+	select {
+	case err := <-flushed:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the export waited for the replay")
+	}
+	assert.Len(t, queuedFiles(t, dir), 2)
+
+	close(release)
+	require.Eventually(t, func() bool {
+		return len(queuedFiles(t, dir)) == 0
+	}, 10*time.Second, 50*time.Millisecond)
+}
+
+func TestForceFlushExportsWithoutShuttingDown(t *testing.T) {
+	traceServer := &dummyTraceServer{}
+	stopper := dummyGRPCListenerWithTraceServer(traceServer)
+	defer stopper()
+
+	otelConfig, err := Configure(
+		WithLogger(&testLogger{}),
+		// make sure spans are only exported when flushed
+		WithBatchSpanProcessorScheduleDelay(time.Hour),
+		withTestExporters(),
+	)
+	require.NoError(t, err)
+	defer otelConfig.Shutdown()
+
+	tracer := otel.GetTracerProvider().Tracer("otelconfig-tests")
+	_, span := tracer.Start(context.Background(), "first-span")
+	span.End()
+	require.NoError(t, otelConfig.ForceFlush(context.Background()))
+	require.Len(t, traceServer.requests(), 1)
+
+	// the pipeline keeps working after a flush
+	_, span = tracer.Start(context.Background(), "second-span")
+	span.End()
+	require.NoError(t, otelConfig.ForceFlush(context.Background()))
+	require.Len(t, traceServer.requests(), 2)
+	spans := traceServer.requests()[1].ResourceSpans[0].ScopeSpans[0].Spans
+	assert.Equal(t, "second-span", spans[0].Name)
+}
+
 func TestThatEndpointsFallBackCorrectly(t *testing.T) {
 	testCases := []struct {
 		name            string
@@ -906,6 +1853,8 @@ func TestThatEndpointsFallBackCorrectly(t *testing.T) {
 		tracesInsecure  bool
 		metricsEndpoint string
 		metricsInsecure bool
+		logsEndpoint    string
+		logsInsecure    bool
 	}{
 		{
 			name:            "defaults",
@@ -914,6 +1863,8 @@ func TestThatEndpointsFallBackCorrectly(t *testing.T) {
 			tracesInsecure:  false,
 			metricsEndpoint: "localhost:4317",
 			metricsInsecure: false,
+			logsEndpoint:    "localhost:4317",
+			logsInsecure:    false,
 		},
 		{
 			name: "set generic endpoint / insecure",
@@ -925,6 +1876,8 @@ func TestThatEndpointsFallBackCorrectly(t *testing.T) {
 			tracesInsecure:  true,
 			metricsEndpoint: "generic-url:4317",
 			metricsInsecure: true,
+			logsEndpoint:    "generic-url:4317",
+			logsInsecure:    true,
 		},
 		{
 			name: "set specific endpoint / insecure",
@@ -934,11 +1887,15 @@ func TestThatEndpointsFallBackCorrectly(t *testing.T) {
 				WithTracesExporterInsecure(true),
 				WithMetricsExporterEndpoint("metrics-url:1234"),
 				WithMetricsExporterInsecure(true),
+				WithLogsExporterEndpoint("logs-url"),
+				WithLogsExporterInsecure(true),
 			},
 			tracesEndpoint:  "traces-url:4317",
 			tracesInsecure:  true,
 			metricsEndpoint: "metrics-url:1234",
 			metricsInsecure: true,
+			logsEndpoint:    "logs-url:4317",
+			logsInsecure:    true,
 		},
 		{
 			name: "set traces to protobuf, metrics default",
@@ -950,6 +1907,8 @@ func TestThatEndpointsFallBackCorrectly(t *testing.T) {
 			tracesInsecure:  true,
 			metricsEndpoint: "localhost:4317",
 			metricsInsecure: false,
+			logsEndpoint:    "localhost:4317",
+			logsInsecure:    false,
 		},
 		{
 			name: "set grpc endpoint with https scheme and no port, add port as helper",
@@ -958,6 +1917,7 @@ func TestThatEndpointsFallBackCorrectly(t *testing.T) {
 			},
 			tracesEndpoint:  "generic-url:443",
 			metricsEndpoint: "generic-url:443",
+			logsEndpoint:    "generic-url:443",
 		},
 		{
 			name: "set grpc endpoint with https scheme and port, no update to port",
@@ -966,6 +1926,7 @@ func TestThatEndpointsFallBackCorrectly(t *testing.T) {
 			},
 			tracesEndpoint:  "generic-url:1234",
 			metricsEndpoint: "generic-url:1234",
+			logsEndpoint:    "generic-url:1234",
 		},
 		{
 			name: "set grpc endpoint with http scheme and port, no update to port",
@@ -973,7 +1934,11 @@ func TestThatEndpointsFallBackCorrectly(t *testing.T) {
 				WithExporterProtocol("grpc"),
 			},
 			tracesEndpoint:  "generic-url:1234",
+			tracesInsecure:  true,
 			metricsEndpoint: "generic-url:1234",
+			metricsInsecure: true,
+			logsEndpoint:    "generic-url:1234",
+			logsInsecure:    true,
 		},
 		{
 			name:            "defaults",
@@ -982,6 +1947,8 @@ func TestThatEndpointsFallBackCorrectly(t *testing.T) {
 			tracesInsecure:  false,
 			metricsEndpoint: "localhost:4317",
 			metricsInsecure: false,
+			logsEndpoint:    "localhost:4317",
+			logsInsecure:    false,
 		},
 	}
 
@@ -989,13 +1956,20 @@ func TestThatEndpointsFallBackCorrectly(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			cfg, err := newConfig(tc.configOpts...)
 			assert.NoError(t, err)
-			tracesEndpoint, tracesInsecure := cfg.getTracesEndpoint()
-			assert.Equal(t, tc.tracesEndpoint, tracesEndpoint)
-			assert.Equal(t, tc.tracesInsecure, tracesInsecure)
-
-			metricsEndpoint, metricsInsecure := cfg.getMetricsEndpoint()
-			assert.Equal(t, tc.metricsEndpoint, metricsEndpoint)
-			assert.Equal(t, tc.metricsInsecure, metricsInsecure)
+			tracesEndpoint, err := cfg.getTracesEndpoint()
+			require.NoError(t, err)
+			assert.Equal(t, tc.tracesEndpoint, tracesEndpoint.Host)
+			assert.Equal(t, tc.tracesInsecure, tracesEndpoint.Insecure)
+
+			metricsEndpoint, err := cfg.getMetricsEndpoint()
+			require.NoError(t, err)
+			assert.Equal(t, tc.metricsEndpoint, metricsEndpoint.Host)
+			assert.Equal(t, tc.metricsInsecure, metricsEndpoint.Insecure)
+
+			logsEndpoint, err := cfg.getLogsEndpoint()
+			require.NoError(t, err)
+			assert.Equal(t, tc.logsEndpoint, logsEndpoint.Host)
+			assert.Equal(t, tc.logsInsecure, logsEndpoint.Insecure)
 		})
 	}
 }
@@ -1008,6 +1982,7 @@ func TestHttpProtoDefaultsToCorrectHostAndPort(t *testing.T) {
 	defer ts.Close()
 
 	shutdown, err := ConfigureOpenTelemetry(
+		WithLogsEnabled(true),
 		WithLogger(logger),
 		WithExporterEndpoint(ts.URL),
 		WithExporterInsecure(true),
@@ -1026,6 +2001,363 @@ func TestHttpProtoDefaultsToCorrectHostAndPort(t *testing.T) {
 	logger.requireContains(t, "received data from path: /v1/metrics")
 }
 
+func TestHttpProtoLogsDefaultToCorrectPath(t *testing.T) {
+	logger := &testLogger{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.Debugf("received data from path: %s", r.URL)
+	}))
+	defer ts.Close()
+
+	shutdown, err := ConfigureOpenTelemetry(
+		WithLogsEnabled(true),
+		WithLogger(logger),
+		WithExporterEndpoint(ts.URL),
+		WithExporterInsecure(true),
+		WithExporterProtocol("http/protobuf"),
+		WithTracesEnabled(false),
+		WithMetricsEnabled(false),
+	)
+	require.NoError(t, err)
+
+	var record otellog.Record
+	record.SetBody(otellog.StringValue("test-log"))
+	global.GetLoggerProvider().Logger("otelconfig-tests").Emit(context.Background(), record)
+	shutdown()
+
+	logger.requireContains(t, "received data from path: /v1/logs")
+}
+
+func TestHttpJSONExportsTracesMetricsAndLogs(t *testing.T) {
+	var mu sync.Mutex
+	contentTypes := map[string]string{}
+	bodies := map[string][]byte{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gz, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(gz)
+		require.NoError(t, err)
+
+		mu.Lock()
+		defer mu.Unlock()
+		contentTypes[r.URL.Path] = r.Header.Get("Content-Type")
+		bodies[r.URL.Path] = body
+	}))
+	defer ts.Close()
+
+	shutdown, err := ConfigureOpenTelemetry(
+		WithLogsEnabled(true),
+		WithExporterEndpoint(ts.URL),
+		WithExporterInsecure(true),
+		WithExporterProtocol("http/json"),
+	)
+	require.NoError(t, err)
+
+	tracer := otel.GetTracerProvider().Tracer("otelconfig-tests")
+	_, span := tracer.Start(context.Background(), "test-span")
+	span.End()
+	var record otellog.Record
+	record.SetBody(otellog.StringValue("test-log"))
+	global.GetLoggerProvider().Logger("otelconfig-tests").Emit(context.Background(), record)
+	shutdown()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, "application/json", contentTypes["/v1/traces"])
+	assert.Equal(t, "application/json", contentTypes["/v1/metrics"])
+	assert.Contains(t, string(bodies["/v1/metrics"]), `"resourceMetrics"`)
+	assert.Equal(t, "application/json", contentTypes["/v1/logs"])
+	assert.Contains(t, string(bodies["/v1/logs"]), `"stringValue":"test-log"`)
+
+	var traces struct {
+		ResourceSpans []struct {
+			ScopeSpans []struct {
+				Spans []struct {
+					TraceID string `json:"traceId"`
+					SpanID  string `json:"spanId"`
+					Name    string `json:"name"`
+					Kind    int    `json:"kind"`
+				} `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	require.NoError(t, json.Unmarshal(bodies["/v1/traces"], &traces))
+	require.Len(t, traces.ResourceSpans, 1)
+	require.Len(t, traces.ResourceSpans[0].ScopeSpans, 1)
+	require.Len(t, traces.ResourceSpans[0].ScopeSpans[0].Spans, 1)
+	exported := traces.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	assert.Equal(t, "test-span", exported.Name)
+	assert.Equal(t, span.SpanContext().TraceID().String(), exported.TraceID)
+	assert.Equal(t, span.SpanContext().SpanID().String(), exported.SpanID)
+	assert.Equal(t, 1, exported.Kind, "span kind should be encoded as an integer")
+}
+
+// testCertificate is a certificate and key written to PEM files for TLS tests.
+type testCertificate struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	certFile string
+	keyFile  string
+}
+
+// newTestCertificate creates a certificate from template, signed by parent or self-signed
+// if parent is nil, and writes it and its key to PEM files in a temporary directory.
+func newTestCertificate(t *testing.T, name string, template *x509.Certificate, parent *testCertificate) *testCertificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.Subject = pkix.Name{CommonName: name}
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	signerCert, signerKey := template, key
+	if parent != nil {
+		signerCert, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCert, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	tc := &testCertificate{
+		cert:     cert,
+		key:      key,
+		certFile: filepath.Join(dir, name+".crt"),
+		keyFile:  filepath.Join(dir, name+".key"),
+	}
+	require.NoError(t, os.WriteFile(tc.certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(tc.keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return tc
+}
+
+func TestMutualTLSFromEnvironment(t *testing.T) {
+	ca := newTestCertificate(t, "ca", &x509.Certificate{
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+	serverCert := newTestCertificate(t, "server", &x509.Certificate{
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca)
+	clientCert := newTestCertificate(t, "client", &x509.Certificate{
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+	serverKeyPair, err := tls.LoadX509KeyPair(serverCert.certFile, serverCert.keyFile)
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var clientNames []string
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		clientNames = append(clientNames, r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	ts.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverKeyPair},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	ts.StartTLS()
+	defer ts.Close()
+
+	setenv("OTEL_EXPORTER_OTLP_CERTIFICATE", ca.certFile)
+	setenv("OTEL_EXPORTER_OTLP_TRACES_CLIENT_CERTIFICATE", clientCert.certFile)
+	setenv("OTEL_EXPORTER_OTLP_TRACES_CLIENT_KEY", clientCert.keyFile)
+	defer unsetAllOtelEnvironmentVariables()
+
+	otelConfig, err := Configure(
+		WithLogger(&testLogger{}),
+		WithTracesExporterEndpoint(ts.URL),
+		WithExporterProtocol("http/protobuf"),
+		WithMetricsEnabled(false),
+		WithLogsEnabled(false),
+	)
+	require.NoError(t, err)
+	defer otelConfig.Shutdown()
+
+	_, span := otel.GetTracerProvider().Tracer("otelconfig-tests").Start(context.Background(), "test-span")
+	span.End()
+	require.NoError(t, otelConfig.ForceFlush(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"client"}, clientNames)
+}
+
+func TestInvalidTLSConfiguration(t *testing.T) {
+	testCases := []struct {
+		name     string
+		env      map[string]string
+		variable string
+		error    string
+	}{
+		{
+			name:     "missing certificate file",
+			env:      map[string]string{"OTEL_EXPORTER_OTLP_CERTIFICATE": "/does/not/exist.crt"},
+			variable: "OTEL_EXPORTER_OTLP_CERTIFICATE",
+			error:    "failed to read certificate",
+		},
+		{
+			name:     "client certificate without key",
+			env:      map[string]string{"OTEL_EXPORTER_OTLP_METRICS_CLIENT_CERTIFICATE": "/does/not/exist.crt"},
+			variable: "OTEL_EXPORTER_OTLP_METRICS_CLIENT_CERTIFICATE",
+			error:    "client certificate and client key must be set together",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				setenv(k, v)
+			}
+			defer unsetAllOtelEnvironmentVariables()
+
+			shutdown, err := ConfigureOpenTelemetry(
+				WithLogger(&testLogger{}),
+				withTestExporters(),
+			)
+			defer shutdown()
+			assert.ErrorContains(t, err, fmt.Sprintf("setup error: invalid configuration: %s=%q: %s", tc.variable, tc.env[tc.variable], tc.error))
+			var configErr *ConfigError
+			require.ErrorAs(t, err, &configErr)
+			assert.Equal(t, tc.variable, configErr.Variable)
+		})
+	}
+}
+
+func TestTLSConfigOptions(t *testing.T) {
+	generic := &tls.Config{ServerName: "generic"}
+	traces := &tls.Config{ServerName: "traces"}
+	c, err := newConfig(
+		WithTLSConfig(generic),
+		WithTracesTLSConfig(traces),
+	)
+	require.NoError(t, err)
+
+	tracesTLSConfig, err := c.getTracesTLSConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "traces", tracesTLSConfig.ServerName)
+	assert.NotSame(t, traces, tracesTLSConfig, "options are copied, not modified")
+
+	metricsTLSConfig, err := c.getMetricsTLSConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "generic", metricsTLSConfig.ServerName)
+	assert.Nil(t, metricsTLSConfig.RootCAs, "system roots are still used")
+
+	c, err = newConfig()
+	require.NoError(t, err)
+	logsTLSConfig, err := c.getLogsTLSConfig()
+	require.NoError(t, err)
+	assert.Nil(t, logsTLSConfig, "exporter defaults are used when nothing is configured")
+}
+
+func TestHttpCompression(t *testing.T) {
+	var mu sync.Mutex
+	encodings := map[string]string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		encodings[r.URL.Path] = r.Header.Get("Content-Encoding")
+	}))
+	defer ts.Close()
+
+	shutdown, err := ConfigureOpenTelemetry(
+		WithLogger(&testLogger{}),
+		WithExporterEndpoint(ts.URL),
+		WithExporterInsecure(true),
+		WithExporterProtocol("http/protobuf"),
+		WithExporterCompression(CompressionNone),
+		WithMetricsExporterCompression(CompressionGzip),
+		WithLogsEnabled(false),
+	)
+	require.NoError(t, err)
+
+	_, span := otel.GetTracerProvider().Tracer("otelconfig-tests").Start(context.Background(), "test-span")
+	span.End()
+	shutdown()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, map[string]string{"/v1/traces": "", "/v1/metrics": "gzip"}, encodings)
+}
+
+func TestUnsupportedCompression(t *testing.T) {
+	shutdown, err := ConfigureOpenTelemetry(
+		WithLogger(&testLogger{}),
+		WithExporterProtocol("http/protobuf"),
+		WithTracesExporterCompression("zstd"),
+		withTestExporters(),
+	)
+	defer shutdown()
+	assert.ErrorContains(t, err, `unsupported compression "zstd"`)
+	var configErr *ConfigError
+	require.ErrorAs(t, err, &configErr)
+	assert.Equal(t, "OTEL_EXPORTER_OTLP_TRACES_COMPRESSION", configErr.Variable)
+
+	shutdown, err = ConfigureOpenTelemetry(
+		WithLogger(&testLogger{}),
+		WithTracesExporterCompression("not-registered"),
+		withTestExporters(),
+	)
+	defer shutdown()
+	assert.ErrorContains(t, err, `unsupported compression "not-registered"`)
+}
+
+// countingCompressor is a gRPC compressor that doesn't compress, but counts how often it is used.
+type countingCompressor struct {
+	used atomic.Int32
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+func (c *countingCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	c.used.Add(1)
+	return nopWriteCloser{w}, nil
+}
+
+func (c *countingCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	return r, nil
+}
+
+func (c *countingCompressor) Name() string {
+	return "counting"
+}
+
+func TestRegisteredGRPCCompressor(t *testing.T) {
+	compressor := &countingCompressor{}
+	encoding.RegisterCompressor(compressor)
+
+	stopper := dummyGRPCListener()
+	defer stopper()
+
+	otelConfig, err := Configure(
+		WithLogger(&testLogger{}),
+		WithTracesExporterCompression(compressor.Name()),
+		WithMetricsEnabled(false),
+		WithLogsEnabled(false),
+		withTestExporters(),
+	)
+	require.NoError(t, err)
+	defer otelConfig.Shutdown()
+
+	_, span := otel.GetTracerProvider().Tracer("otelconfig-tests").Start(context.Background(), "test-span")
+	span.End()
+	require.NoError(t, otelConfig.ForceFlush(context.Background()))
+	assert.Positive(t, compressor.used.Load())
+}
+
 func TestCanConfigureCustomSampler(t *testing.T) {
 	sampler := &testSampler{}
 	config, err := newConfig(
@@ -1036,6 +2368,85 @@ func TestCanConfigureCustomSampler(t *testing.T) {
 	assert.Same(t, config.Sampler, sampler)
 }
 
+func TestSamplerFromEnvironment(t *testing.T) {
+	testCases := []struct {
+		name     string
+		sampler  string
+		arg      string
+		expected trace.Sampler
+	}{
+		{name: "always on", sampler: "always_on", expected: trace.AlwaysSample()},
+		{name: "always off", sampler: "always_off", expected: trace.NeverSample()},
+		{name: "ratio", sampler: "traceidratio", arg: "0.25", expected: trace.TraceIDRatioBased(0.25)},
+		{name: "ratio defaults to 1", sampler: "traceidratio", expected: trace.TraceIDRatioBased(1)},
+		{name: "parent based always on", sampler: "parentbased_always_on", expected: trace.ParentBased(trace.AlwaysSample())},
+		{name: "parent based always off", sampler: "parentbased_always_off", expected: trace.ParentBased(trace.NeverSample())},
+		{name: "parent based ratio", sampler: "parentbased_traceidratio", arg: "0.5", expected: trace.ParentBased(trace.TraceIDRatioBased(0.5))},
+		{name: "arg ignored for non-ratio sampler", sampler: "always_off", arg: "bleargh", expected: trace.NeverSample()},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			setenv("OTEL_TRACES_SAMPLER", tc.sampler)
+			setenv("OTEL_TRACES_SAMPLER_ARG", tc.arg)
+			defer unsetAllOtelEnvironmentVariables()
+
+			config, err := newConfig(WithSampler(&testSampler{}))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected.Description(), config.Sampler.Description())
+		})
+	}
+}
+
+func TestInvalidSamplerFromEnvironment(t *testing.T) {
+	testCases := []struct {
+		name     string
+		sampler  string
+		arg      string
+		expected string
+	}{
+		{name: "unknown sampler", sampler: "sometimes", expected: `unsupported sampler "sometimes"`},
+		{name: "unparsable ratio", sampler: "traceidratio", arg: "half", expected: `sampler argument "half" must be a number between 0 and 1`},
+		{name: "ratio out of range", sampler: "parentbased_traceidratio", arg: "1.5", expected: `sampler argument "1.5" must be a number between 0 and 1`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			setenv("OTEL_TRACES_SAMPLER", tc.sampler)
+			setenv("OTEL_TRACES_SAMPLER_ARG", tc.arg)
+			defer unsetAllOtelEnvironmentVariables()
+
+			_, err := ConfigureOpenTelemetry(WithLogger(&testLogger{}))
+			assert.ErrorContains(t, err, tc.expected)
+		})
+	}
+}
+
+func TestBatchSpanProcessorConfig(t *testing.T) {
+	setenv("OTEL_BSP_SCHEDULE_DELAY", "250")
+	setenv("OTEL_BSP_MAX_QUEUE_SIZE", "8192")
+	defer unsetAllOtelEnvironmentVariables()
+
+	config, err := newConfig(
+		WithBatchSpanProcessorScheduleDelay(time.Second),
+		WithBatchSpanProcessorExportTimeout(5*time.Second),
+		WithBatchSpanProcessorMaxExportBatchSize(1024),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 250, config.BSPScheduleDelay, "environment beats code")
+	assert.Equal(t, 5000, config.BSPExportTimeout)
+	assert.Equal(t, 8192, config.BSPMaxQueueSize)
+	assert.Equal(t, 1024, config.BSPMaxExportBatchSize)
+}
+
+func TestInvalidBatchSpanProcessorConfig(t *testing.T) {
+	shutdown, err := ConfigureOpenTelemetry(
+		WithLogger(&testLogger{}),
+		WithBatchSpanProcessorMaxQueueSize(-1),
+		withTestExporters(),
+	)
+	defer shutdown()
+	assert.ErrorContains(t, err, "setup error: invalid batch span processor configuration")
+}
+
 func TestCanUseCustomSampler(t *testing.T) {
 	expectedSamplerProvidedAttribute := attribute.String("test", "value")
 	sampler := &testSampler{
@@ -1060,7 +2471,7 @@ func TestCanUseCustomSampler(t *testing.T) {
 	span.End()
 	shutdown()
 
-	spans := traceServer.recievedExportTraceServiceRequests[0].ResourceSpans[0].ScopeSpans[0].Spans
+	spans := traceServer.requests()[0].ResourceSpans[0].ScopeSpans[0].Spans
 	require.Equal(t, 1, len(spans), "Should only be one span")
 
 	attrs := spans[0].Attributes
@@ -1155,25 +2566,36 @@ func setenv(key string, value string) {
 
 // A map of the settings used to test configuring OpenTelemetry via environment variables.
 var environmentOtelSettings = map[string]string{
-	"OTEL_SERVICE_NAME":                   "test-service-name",
-	"OTEL_SERVICE_VERSION":                "test-service-version",
-	"OTEL_RESOURCE_ATTRIBUTES":            "an.env.attr=hi,resource.clobber=ENV_WON",
-	"OTEL_LOG_LEVEL":                      "debug",
-	"OTEL_PROPAGATORS":                    "b3,w3c",
-	"OTEL_EXPORTER_OTLP_ENDPOINT":         "http://generic-url",
-	"OTEL_EXPORTER_OTLP_INSECURE":         "true",
-	"OTEL_EXPORTER_OTLP_HEADERS":          "env-headers=present,header-clobber=ENV_WON",
-	"OTEL_EXPORTER_OTLP_PROTOCOL":         "http/protobuf",
-	"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT":  "http://traces-url",
-	"OTEL_EXPORTER_OTLP_TRACES_INSECURE":  "true",
-	"OTEL_EXPORTER_OTLP_TRACES_HEADERS":   "env-traces-headers=present,header-clobber=ENV_WON",
-	"OTEL_EXPORTER_OTLP_TRACES_PROTOCOL":  "http/protobuf",
-	"OTEL_EXPORTER_OTLP_METRICS_ENDPOINT": "http://metrics-url",
-	"OTEL_EXPORTER_OTLP_METRICS_INSECURE": "true",
-	"OTEL_EXPORTER_OTLP_METRICS_HEADERS":  "env-metrics-headers=present,header-clobber=ENV_WON",
-	"OTEL_EXPORTER_OTLP_METRICS_PROTOCOL": "http/protobuf",
-	"OTEL_EXPORTER_OTLP_METRICS_PERIOD":   "42s",
-	"OTEL_METRICS_ENABLED":                "false",
+	"OTEL_SERVICE_NAME":                         "test-service-name",
+	"OTEL_SERVICE_VERSION":                      "test-service-version",
+	"OTEL_RESOURCE_ATTRIBUTES":                  "an.env.attr=hi,resource.clobber=ENV_WON",
+	"OTEL_LOG_LEVEL":                            "debug",
+	"OTEL_PROPAGATORS":                          "b3,w3c",
+	"OTEL_EXPORTER_OTLP_ENDPOINT":               "http://generic-url",
+	"OTEL_EXPORTER_OTLP_INSECURE":               "true",
+	"OTEL_EXPORTER_OTLP_HEADERS":                "env-headers=present,header-clobber=ENV_WON",
+	"OTEL_EXPORTER_OTLP_PROTOCOL":               "http/protobuf",
+	"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT":        "http://traces-url",
+	"OTEL_EXPORTER_OTLP_TRACES_INSECURE":        "true",
+	"OTEL_EXPORTER_OTLP_TRACES_HEADERS":         "env-traces-headers=present,header-clobber=ENV_WON",
+	"OTEL_EXPORTER_OTLP_TRACES_PROTOCOL":        "http/protobuf",
+	"OTEL_EXPORTER_OTLP_METRICS_ENDPOINT":       "http://metrics-url",
+	"OTEL_EXPORTER_OTLP_METRICS_INSECURE":       "true",
+	"OTEL_EXPORTER_OTLP_METRICS_HEADERS":        "env-metrics-headers=present,header-clobber=ENV_WON",
+	"OTEL_EXPORTER_OTLP_METRICS_PROTOCOL":       "http/protobuf",
+	"OTEL_EXPORTER_OTLP_METRICS_PERIOD":         "42s",
+	"OTEL_METRICS_ENABLED":                      "false",
+	"OTEL_EXPORTER_OTLP_LOGS_ENDPOINT":          "http://logs-url",
+	"OTEL_EXPORTER_OTLP_LOGS_INSECURE":          "true",
+	"OTEL_EXPORTER_OTLP_LOGS_HEADERS":           "env-logs-headers=present,header-clobber=ENV_WON",
+	"OTEL_EXPORTER_OTLP_LOGS_PROTOCOL":          "http/protobuf",
+	"OTEL_EXPORTER_OTLP_COMPRESSION":            "none",
+	"OTEL_EXPORTER_OTLP_LOGS_COMPRESSION":       "gzip",
+	"OTEL_EXPORTER_OTLP_TIMEOUT":                "5000",
+	"OTEL_EXPORTER_OTLP_METRICS_TIMEOUT":        "2000",
+	"OTEL_EXPORTER_OTLP_RETRY_INITIAL_INTERVAL": "500",
+	"OTEL_TRACES_EXPORTER":                      "otlp,console",
+	"OTEL_EXPORTER_PROMETHEUS_PORT":             "9465",
 }
 
 // setEnvironment sets OTEL_ environment variables for testing config via environment.
@@ -1,6 +1,24 @@
 package pipelines
 
 import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+
+	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
 )
@@ -15,18 +33,263 @@ const (
 	ProtocolHTTPJSON     Protocol = "http/json"
 )
 
+// These are the compression values supported by every protocol. The gRPC exporters
+// also accept the name of any other compressor registered with grpc/encoding.
+const (
+	CompressionGzip = "gzip"
+	CompressionNone = "none"
+)
+
+// These are the exporters that can be listed in PipelineConfig.Exporters.
+const (
+	// ExporterOTLP sends telemetry to the configured endpoint using the configured protocol.
+	ExporterOTLP = "otlp"
+	// ExporterConsole pretty-prints telemetry to the console.
+	ExporterConsole = "console"
+	// ExporterNone doesn't export anything.
+	ExporterNone = "none"
+	// ExporterFile appends telemetry to PipelineConfig.FileWriter as OTLP/JSON, one request per line.
+	ExporterFile = "file"
+	// ExporterPrometheus serves metrics for Prometheus to scrape. It can only be used for metrics.
+	ExporterPrometheus = "prometheus"
+)
+
 // PipelineConfig contains config info for a Pipeline.
 type PipelineConfig struct {
-	Protocol        Protocol
-	Endpoint        string
-	Insecure        bool
-	Headers         map[string]string
+	Protocol Protocol
+	Endpoint string
+	// Socket, if set, is the path of a Unix domain socket the OTLP exporters connect to;
+	// Endpoint is then only used as the host name of requests.
+	Socket string
+	// URLPath is the path the HTTP exporters post to; empty uses the exporter's default.
+	URLPath  string
+	Insecure bool
+	Headers  map[string]string
+	// HeaderProvider, if set, is called for every export request for headers to add to
+	// those in Headers, taking precedence over them. It isn't used with GRPCConn.
+	HeaderProvider  HeaderProvider
 	Resource        *resource.Resource
 	ReportingPeriod string
 	Propagators     []string
 	SpanProcessors  []trace.SpanProcessor
 	Sampler         trace.Sampler
+	// Batch span processor settings; zero values use the SDK defaults.
+	BSPScheduleDelay      time.Duration
+	BSPExportTimeout      time.Duration
+	BSPMaxQueueSize       int
+	BSPMaxExportBatchSize int
+	// TLSConfig is used for secure connections; nil uses the system root CAs.
+	TLSConfig *tls.Config
+	// HTTPClient, if set, sends the requests of the HTTP exporters. Otherwise HTTPTransport,
+	// if set, is the transport of their client. Either is used as is, so the TLS and proxy
+	// settings are up to them, and Socket is ignored.
+	HTTPClient    *http.Client
+	HTTPTransport http.RoundTripper
+	// GRPCDialOptions are added to the dial options of the gRPC exporters, after their own.
+	GRPCDialOptions []grpc.DialOption
+	// GRPCConn, if set, is the connection the gRPC exporters use instead of dialing the
+	// endpoint, so the credentials, compression and dial options come from it. The
+	// exporters don't close it.
+	GRPCConn *grpc.ClientConn
+	// Compression is applied to export requests; empty means gzip.
+	Compression string
+	// Timeout bounds each export request; zero means 10 seconds.
+	Timeout time.Duration
+	// Retry settings for failed exports; zero durations use the exporter defaults.
+	RetryDisabled        bool
+	RetryInitialInterval time.Duration
+	RetryMaxInterval     time.Duration
+	RetryMaxElapsedTime  time.Duration
+	// Exporters lists the exporters telemetry is sent to; empty means OTLP only.
+	Exporters []string
+	// ConsoleWriter receives the output of the console exporter; nil means standard output.
+	ConsoleWriter io.Writer
+	// PrometheusHost and PrometheusPort are where the Prometheus exporter serves /metrics.
+	PrometheusHost string
+	PrometheusPort int
+	// FileWriter receives the output of the file exporter. It is shared between signals,
+	// so it must be safe for concurrent use.
+	FileWriter io.Writer
+	// QueueDir enables a persistent queue for the OTLP span exporter: batches that fail to
+	// export with an error worth retrying are spooled to this directory and replayed in
+	// order every RetryInitialInterval.
+	// QueueMaxBytes caps its size, dropping the oldest batches first; zero means no limit.
+	QueueDir      string
+	QueueMaxBytes int64
+	// Views change the metrics recorded by the MeterProvider.
+	Views []metric.View
+	// Reloader, if set, can rebuild the OTLP exporters while the pipeline is running.
+	Reloader *Reloader
 }
 
 // PipelineSetupFunc defines the interface for a Pipeline Setup function.
 type PipelineSetupFunc func(PipelineConfig) (func() error, error)
+
+// PipelineSetupWithContextFunc defines the interface for a Pipeline Setup function whose
+// shutdown function takes a context.
+type PipelineSetupWithContextFunc func(PipelineConfig) (func(context.Context) error, error)
+
+// withoutContext adapts the result of a PipelineSetupWithContextFunc to a
+// PipelineSetupFunc, shutting down with a background context.
+func withoutContext(shutdown func(context.Context) error, err error) (func() error, error) {
+	if err != nil {
+		return nil, err
+	}
+	return func() error {
+		return shutdown(context.Background())
+	}, nil
+}
+
+// grpcCredentials returns the credentials used by the gRPC exporters for secure connections.
+func grpcCredentials(tlsConfig *tls.Config) credentials.TransportCredentials {
+	if tlsConfig == nil {
+		return credentials.NewClientTLSFromCert(nil, "")
+	}
+	return credentials.NewTLS(tlsConfig)
+}
+
+// httpTLSConfig returns the TLS configuration used by the HTTP exporters for secure connections.
+func httpTLSConfig(tlsConfig *tls.Config) *tls.Config {
+	if tlsConfig == nil {
+		return &tls.Config{}
+	}
+	return tlsConfig.Clone()
+}
+
+// exporterNames returns the exporters to set up, leaving out none.
+func exporterNames(exporters []string) []string {
+	if len(exporters) == 0 {
+		return []string{ExporterOTLP}
+	}
+	var names []string
+	for _, name := range exporters {
+		name = strings.TrimSpace(name)
+		if name != "" && name != ExporterNone {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// consoleWriter returns where the console exporter writes to.
+func consoleWriter(c PipelineConfig) io.Writer {
+	if c.ConsoleWriter == nil {
+		return os.Stdout
+	}
+	return c.ConsoleWriter
+}
+
+// defaultExportTimeout is the OTLP exporters' own default timeout.
+const defaultExportTimeout = 10 * time.Second
+
+// exportTimeout returns the timeout for each export request.
+func exportTimeout(timeout time.Duration) time.Duration {
+	if timeout <= 0 {
+		return defaultExportTimeout
+	}
+	return timeout
+}
+
+// retryConfig has the same fields as the RetryConfig of every OTLP exporter, so it can be
+// converted to any of them.
+type retryConfig struct {
+	Enabled         bool
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	MaxElapsedTime  time.Duration
+}
+
+// newRetryConfig returns the retry policy for the exporters, using the OTLP exporters'
+// own defaults for any interval that isn't set.
+func newRetryConfig(c PipelineConfig) retryConfig {
+	rc := retryConfig{
+		Enabled:         !c.RetryDisabled,
+		InitialInterval: 5 * time.Second,
+		MaxInterval:     30 * time.Second,
+		MaxElapsedTime:  time.Minute,
+	}
+	if c.RetryInitialInterval > 0 {
+		rc.InitialInterval = c.RetryInitialInterval
+	}
+	if c.RetryMaxInterval > 0 {
+		rc.MaxInterval = c.RetryMaxInterval
+	}
+	if c.RetryMaxElapsedTime > 0 {
+		rc.MaxElapsedTime = c.RetryMaxElapsedTime
+	}
+	return rc
+}
+
+// grpcDialOptions returns the dial options of the gRPC exporters, which take them all at once.
+func grpcDialOptions(c PipelineConfig) ([]grpc.DialOption, error) {
+	compression, err := grpcCompression(c.Compression)
+	if err != nil {
+		return nil, err
+	}
+	opts := []grpc.DialOption{compression}
+	if c.Socket != "" {
+		opts = append(opts, grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return dialSocket(ctx, c.Socket)
+		}))
+	}
+	if c.HeaderProvider != nil {
+		if err := checkHeaderProvider(c); err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.WithPerRPCCredentials(headerCredentials{c.HeaderProvider}))
+	}
+	return append(opts, c.GRPCDialOptions...), nil
+}
+
+// proxyFromEnvironment returns the proxy for a request as set by the HTTPS_PROXY, HTTP_PROXY
+// and NO_PROXY environment variables, or their lowercase versions. Unlike
+// http.ProxyFromEnvironment, they are read again for every request, so that changes are
+// picked up when the configuration is reloaded.
+func proxyFromEnvironment(req *http.Request) (*url.URL, error) {
+	return httpproxy.FromEnvironment().ProxyFunc()(req.URL)
+}
+
+// dialSocket connects to the Unix domain socket at path.
+func dialSocket(ctx context.Context, path string) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, "unix", path)
+}
+
+// grpcCompression returns the dial option that makes the gRPC exporters use the compressor
+// registered for compression. The exporters' own WithCompressor only understands gzip, so the
+// compressor is set as a default call option instead.
+func grpcCompression(compression string) (grpc.DialOption, error) {
+	switch compression {
+	case "", CompressionGzip:
+		compression = gzip.Name
+	case CompressionNone:
+		return grpc.WithDefaultCallOptions(), nil
+	}
+	if encoding.GetCompressor(compression) == nil {
+		return nil, fmt.Errorf("unsupported compression %q: no gRPC compressor is registered with that name", compression)
+	}
+	return grpc.WithDefaultCallOptions(grpc.UseCompressor(compression)), nil
+}
+
+// CheckCompression returns an error if the exporters of protocol can't apply compression.
+func CheckCompression(protocol Protocol, compression string) error {
+	var err error
+	if protocol == ProtocolGRPC {
+		_, err = grpcCompression(compression)
+	} else {
+		_, err = httpGzip(compression)
+	}
+	return err
+}
+
+// httpGzip reports whether the HTTP exporters should gzip requests for compression.
+func httpGzip(compression string) (bool, error) {
+	switch compression {
+	case "", CompressionGzip:
+		return true, nil
+	case CompressionNone:
+		return false, nil
+	default:
+		return false, fmt.Errorf("unsupported compression %q: only gzip and none are supported over HTTP", compression)
+	}
+}
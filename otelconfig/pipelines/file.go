@@ -0,0 +1,187 @@
+package pipelines
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric"
+)
+
+// FileWriter appends to a file, rotating it once it would grow beyond a maximum size.
+// It is safe for concurrent use, and the data passed to a single Write is never split
+// across two files.
+type FileWriter struct {
+	mu         sync.Mutex
+	path       string
+	maxBytes   int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// NewFileWriter opens the file at path for appending, creating it and its directory if
+// needed. Once the file would grow beyond maxBytes, it is renamed to path.1, path.1 to
+// path.2 and so on, keeping at most maxBackups old files. A maxBytes of zero disables
+// rotation.
+func NewFileWriter(path string, maxBytes int64, maxBackups int) (*FileWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	w := &FileWriter{
+		path:       path,
+		maxBytes:   maxBytes,
+		maxBackups: maxBackups,
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *FileWriter) open() error {
+	file, err := os.OpenFile(w.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+// Write appends p to the file, rotating it first if p doesn't fit. If rotating fails, p
+// is appended to the current file, and the failure goes to the error handler.
+func (w *FileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+	if w.maxBytes > 0 && w.size > 0 && w.size+int64(len(p)) > w.maxBytes {
+		if err := w.rotate(); err != nil {
+			err = fmt.Errorf("failed to rotate %s: %w", w.path, err)
+			if w.file == nil {
+				return 0, err
+			}
+			otel.Handle(err)
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// rotate moves the current file out of the way, dropping the oldest backup, and starts
+// a new one. If that fails, it reopens the current file, so that writes can go on.
+func (w *FileWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.file = nil
+
+	if err := w.moveAside(); err != nil {
+		if openErr := w.open(); openErr != nil {
+			return errors.Join(err, openErr)
+		}
+		return err
+	}
+	return w.open()
+}
+
+// moveAside renames the file and its backups, or removes the file if there are none.
+func (w *FileWriter) moveAside() error {
+	if w.maxBackups <= 0 {
+		return os.Remove(w.path)
+	}
+	backup := func(i int) string {
+		return fmt.Sprintf("%s.%d", w.path, i)
+	}
+	if err := os.Remove(backup(w.maxBackups)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	for i := w.maxBackups - 1; i > 0; i-- {
+		if err := os.Rename(backup(i), backup(i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return os.Rename(w.path, backup(1))
+}
+
+// Close closes the file. Writes after Close fail.
+func (w *FileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// fileClient writes OTLP export requests as OTLP/JSON, one request per line, in the same
+// format as the collector's file exporter.
+type fileClient struct {
+	w io.Writer
+}
+
+func newFileClient(c PipelineConfig) (*fileClient, error) {
+	if c.FileWriter == nil {
+		return nil, errors.New("the file exporter requires a FileWriter")
+	}
+	return &fileClient{w: c.FileWriter}, nil
+}
+
+func (c *fileClient) export(ctx context.Context, msg proto.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	line, err := marshalOTLPJSON(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal OTLP/JSON request: %w", err)
+	}
+	// write the whole line at once so that it doesn't get mixed up with other signals
+	_, err = c.w.Write(append(line, '\n'))
+	return err
+}
+
+// close does nothing, as the writer is shared between the signals and closed by its owner.
+func (c *fileClient) close() {}
+
+func newFileTraceExporter(c PipelineConfig) (*otlptrace.Exporter, error) {
+	client, err := newFileClient(c)
+	if err != nil {
+		return nil, err
+	}
+	return otlptrace.New(context.Background(), protoTraceClient{client})
+}
+
+func newFileMetricsExporter(c PipelineConfig) (metric.Exporter, error) {
+	client, err := newFileClient(c)
+	if err != nil {
+		return nil, err
+	}
+	return &protoMetricsExporter{client: client}, nil
+}
+
+func newFileLogsExporter(c PipelineConfig) (log.Exporter, error) {
+	client, err := newFileClient(c)
+	if err != nil {
+		return nil, err
+	}
+	return &protoLogsExporter{client: client}, nil
+}
@@ -0,0 +1,93 @@
+package pipelines
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// HeaderProvider returns headers to add to an export request. It is called for every
+// request, so it can return credentials that change while the process runs.
+type HeaderProvider func(ctx context.Context) (map[string]string, error)
+
+// checkHeaderProvider returns an error if the headers of c's HeaderProvider, which
+// usually carry credentials, would be sent in plain text: over an insecure connection
+// that isn't to a Unix domain socket.
+func checkHeaderProvider(c PipelineConfig) error {
+	if c.HeaderProvider != nil && c.Insecure && c.Socket == "" {
+		return fmt.Errorf("the headers of the header provider would be sent unencrypted to %s: "+
+			"use a secure endpoint or a Unix domain socket", c.Endpoint)
+	}
+	return nil
+}
+
+// headerTransport adds the headers of a HeaderProvider to every request sent through it.
+type headerTransport struct {
+	base     http.RoundTripper
+	provider HeaderProvider
+}
+
+func (t headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	headers, err := t.provider(req.Context())
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// CloseIdleConnections closes the idle connections of the base transport, if it can.
+func (t headerTransport) CloseIdleConnections() {
+	if base, ok := t.base.(interface{ CloseIdleConnections() }); ok {
+		base.CloseIdleConnections()
+	}
+}
+
+// withHeaderTransport returns a client that sends its requests through a headerTransport,
+// leaving client as it is.
+func withHeaderTransport(client *http.Client, provider HeaderProvider) *http.Client {
+	wrapped := *client
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	wrapped.Transport = headerTransport{base: base, provider: provider}
+	return &wrapped
+}
+
+// headerCredentials adds the headers of a HeaderProvider to every gRPC call as metadata.
+type headerCredentials struct {
+	provider HeaderProvider
+}
+
+var _ credentials.PerRPCCredentials = headerCredentials{}
+
+// GetRequestMetadata returns the headers of the provider. Its errors are reported as
+// Unavailable rather than gRPC's default of Unauthenticated, so that the exporters retry
+// them as they do over HTTP.
+func (h headerCredentials) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	headers, err := h.provider(ctx)
+	if err != nil {
+		if _, ok := status.FromError(err); !ok {
+			err = status.Errorf(codes.Unavailable, "header provider failed: %v", err)
+		}
+		return nil, err
+	}
+	return headers, nil
+}
+
+// RequireTransportSecurity returns false, as the headers are only added to secure
+// connections or ones to a Unix domain socket, which checkHeaderProvider makes sure of.
+func (headerCredentials) RequireTransportSecurity() bool {
+	return false
+}
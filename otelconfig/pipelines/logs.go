@@ -0,0 +1,152 @@
+package pipelines
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutlog"
+	"go.opentelemetry.io/otel/log/global"
+	"go.opentelemetry.io/otel/sdk/log"
+)
+
+// NewLogsPipeline takes a PipelineConfig and builds a logs pipeline, registering
+// its LoggerProvider globally.
+// It returns a shutdown function that should be called when terminating the pipeline.
+func NewLogsPipeline(c PipelineConfig) (func() error, error) {
+	return withoutContext(NewLogsPipelineWithContext(c))
+}
+
+// NewLogsPipelineWithContext is like NewLogsPipeline, but the context passed to the shutdown
+// function bounds how long the final export may take.
+func NewLogsPipelineWithContext(c PipelineConfig) (func(context.Context) error, error) {
+	loggerProvider, err := NewLoggerProvider(c)
+	if err != nil {
+		return nil, err
+	}
+
+	global.SetLoggerProvider(loggerProvider)
+	return loggerProvider.Shutdown, nil
+}
+
+// NewLoggerProvider creates a LoggerProvider that exports log records in batches as configured,
+// without registering it globally.
+func NewLoggerProvider(c PipelineConfig) (*log.LoggerProvider, error) {
+	logExporters, err := newLogsExporters(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create log exporter: %v", err)
+	}
+
+	opts := []log.LoggerProviderOption{log.WithResource(c.Resource)}
+	for _, logExporter := range logExporters {
+		opts = append(opts, log.WithProcessor(log.NewBatchProcessor(logExporter)))
+	}
+	return log.NewLoggerProvider(opts...), nil
+}
+
+// newLogsExporters creates an exporter for each configured name. If one of them can't be
+// created, those already created are shut down, so that their connections and files are
+// released.
+func newLogsExporters(c PipelineConfig) ([]log.Exporter, error) {
+	var exporters []log.Exporter
+	for _, name := range exporterNames(c.Exporters) {
+		var exporter log.Exporter
+		var err error
+		switch name {
+		case ExporterOTLP:
+			exporter, err = newLogsExporter(c)
+			if err == nil {
+				exporter = reloadableLogsExporter(c, exporter)
+			}
+		case ExporterConsole:
+			exporter, err = stdoutlog.New(stdoutlog.WithWriter(consoleWriter(c)), stdoutlog.WithPrettyPrint())
+		case ExporterFile:
+			exporter, err = newFileLogsExporter(c)
+		default:
+			err = fmt.Errorf("unsupported logs exporter %q", name)
+		}
+		if err != nil {
+			shutdownLogsExporters(exporters)
+			return nil, err
+		}
+		exporters = append(exporters, exporter)
+	}
+	return exporters, nil
+}
+
+// shutdownLogsExporters shuts down exporters that won't be used, ignoring their errors as
+// there is nothing left to export.
+func shutdownLogsExporters(exporters []log.Exporter) {
+	for _, exporter := range exporters {
+		_ = exporter.Shutdown(context.Background())
+	}
+}
+
+func newLogsExporter(c PipelineConfig) (log.Exporter, error) {
+	switch c.Protocol {
+	case ProtocolGRPC:
+		return newGRPCLogsExporter(c)
+	case ProtocolHTTPProtobuf:
+		return newHTTPLogsExporter(c)
+	case ProtocolHTTPJSON:
+		return newOTLPHTTPLogsExporter(c)
+	default:
+		return nil, errors.New("'" + string(c.Protocol) + "' is not a supported protocol")
+	}
+}
+
+func newGRPCLogsExporter(c PipelineConfig) (log.Exporter, error) {
+	dialOptions, err := grpcDialOptions(c)
+	if err != nil {
+		return nil, err
+	}
+	secureOption := otlploggrpc.WithTLSCredentials(grpcCredentials(c.TLSConfig))
+	if c.Insecure {
+		secureOption = otlploggrpc.WithInsecure()
+	}
+	opts := []otlploggrpc.Option{
+		secureOption,
+		otlploggrpc.WithEndpoint(c.Endpoint),
+		otlploggrpc.WithHeaders(c.Headers),
+		otlploggrpc.WithTimeout(exportTimeout(c.Timeout)),
+		otlploggrpc.WithRetry(otlploggrpc.RetryConfig(newRetryConfig(c))),
+		otlploggrpc.WithDialOption(dialOptions...),
+	}
+	if c.GRPCConn != nil {
+		opts = append(opts, otlploggrpc.WithGRPCConn(c.GRPCConn))
+	}
+	return otlploggrpc.New(context.Background(), opts...)
+}
+
+func newHTTPLogsExporter(c PipelineConfig) (log.Exporter, error) {
+	if customHTTPTransport(c) {
+		return newOTLPHTTPLogsExporter(c)
+	}
+	useGzip, err := httpGzip(c.Compression)
+	if err != nil {
+		return nil, err
+	}
+	compression := otlploghttp.NoCompression
+	if useGzip {
+		compression = otlploghttp.GzipCompression
+	}
+	secureOption := otlploghttp.WithTLSClientConfig(httpTLSConfig(c.TLSConfig))
+	if c.Insecure {
+		secureOption = otlploghttp.WithInsecure()
+	}
+	opts := []otlploghttp.Option{
+		secureOption,
+		otlploghttp.WithEndpoint(c.Endpoint),
+		otlploghttp.WithHeaders(c.Headers),
+		otlploghttp.WithTimeout(exportTimeout(c.Timeout)),
+		otlploghttp.WithRetry(otlploghttp.RetryConfig(newRetryConfig(c))),
+		otlploghttp.WithCompression(compression),
+		otlploghttp.WithProxy(proxyFromEnvironment),
+	}
+	if c.URLPath != "" {
+		opts = append(opts, otlploghttp.WithURLPath(c.URLPath))
+	}
+	return otlploghttp.New(context.Background(), opts...)
+}
@@ -2,30 +2,41 @@ package pipelines
 
 import (
 	"context"
-	"crypto/tls"
 	"errors"
 	"fmt"
 	"time"
 
-	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/encoding/gzip"
-
 	hostMetrics "go.opentelemetry.io/contrib/instrumentation/host"
 	runtimeMetrics "go.opentelemetry.io/contrib/instrumentation/runtime"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
 	"go.opentelemetry.io/otel/sdk/metric"
 )
 
-// NewMetricsPipeline takes a PipelineConfig and builds a metrics pipeline.
+// NewMetricsPipeline takes a PipelineConfig and builds a metrics pipeline, registering
+// its MeterProvider globally.
 // It returns a shutdown function that should be called when terminating the pipeline.
 func NewMetricsPipeline(c PipelineConfig) (func() error, error) {
-	metricExporter, err := newMetricsExporter(c.Protocol, c.Endpoint, c.Insecure, c.Headers)
+	return withoutContext(NewMetricsPipelineWithContext(c))
+}
+
+// NewMetricsPipelineWithContext is like NewMetricsPipeline, but the context passed to the shutdown
+// function bounds how long the final export may take.
+func NewMetricsPipelineWithContext(c PipelineConfig) (func(context.Context) error, error) {
+	meterProvider, err := NewMeterProvider(c)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create metric exporter: %v", err)
+		return nil, err
 	}
 
+	otel.SetMeterProvider(meterProvider)
+	return meterProvider.Shutdown, nil
+}
+
+// NewMeterProvider creates a MeterProvider that periodically exports metrics as configured,
+// including the runtime and host metrics, without registering it globally.
+func NewMeterProvider(c PipelineConfig) (*metric.MeterProvider, error) {
 	var readerOpts []metric.PeriodicReaderOption
 	if c.ReportingPeriod != "" {
 		period, err := time.ParseDuration(c.ReportingPeriod)
@@ -38,63 +49,139 @@ func NewMetricsPipeline(c PipelineConfig) (func() error, error) {
 		readerOpts = append(readerOpts, metric.WithInterval(period))
 	}
 
-	meterProvider := metric.NewMeterProvider(
-		metric.WithResource(c.Resource),
-		metric.WithReader(metric.NewPeriodicReader(metricExporter, readerOpts...)))
+	readers, err := newMetricReaders(c, readerOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metric exporter: %v", err)
+	}
+
+	opts := []metric.Option{metric.WithResource(c.Resource), metric.WithView(c.Views...)}
+	for _, reader := range readers {
+		opts = append(opts, metric.WithReader(reader))
+	}
+	meterProvider := metric.NewMeterProvider(opts...)
 
 	if err = runtimeMetrics.Start(runtimeMetrics.WithMeterProvider(meterProvider)); err != nil {
+		_ = meterProvider.Shutdown(context.Background())
 		return nil, fmt.Errorf("failed to start runtime metrics: %v", err)
 	}
 
 	if err = hostMetrics.Start(hostMetrics.WithMeterProvider(meterProvider)); err != nil {
+		_ = meterProvider.Shutdown(context.Background())
 		return nil, fmt.Errorf("failed to start host metrics: %v", err)
 	}
 
-	otel.SetMeterProvider(meterProvider)
-	return func() error {
-		return meterProvider.Shutdown(context.Background())
-	}, nil
+	return meterProvider, nil
+}
+
+// newMetricReaders creates a reader for each configured exporter. Push exporters are read
+// periodically, while the Prometheus exporter is read whenever it is scraped. If one of
+// them can't be created, those already created are shut down, so that the Prometheus
+// exporter stops serving.
+func newMetricReaders(c PipelineConfig, readerOpts []metric.PeriodicReaderOption) ([]metric.Reader, error) {
+	var readers []metric.Reader
+	for _, name := range exporterNames(c.Exporters) {
+		var exporter metric.Exporter
+		var err error
+		switch name {
+		case ExporterOTLP:
+			exporter, err = newMetricsExporter(c)
+			if err == nil {
+				exporter = reloadableMetricsExporter(c, exporter)
+			}
+		case ExporterConsole:
+			exporter, err = stdoutmetric.New(stdoutmetric.WithWriter(consoleWriter(c)), stdoutmetric.WithPrettyPrint())
+		case ExporterFile:
+			exporter, err = newFileMetricsExporter(c)
+		case ExporterPrometheus:
+			var reader *prometheusReader
+			reader, err = newPrometheusReader(c)
+			if err == nil {
+				readers = append(readers, reader)
+				continue
+			}
+		default:
+			err = fmt.Errorf("unsupported metrics exporter %q", name)
+		}
+		if err != nil {
+			shutdownReaders(readers)
+			return nil, err
+		}
+		readers = append(readers, metric.NewPeriodicReader(exporter, readerOpts...))
+	}
+	return readers, nil
+}
+
+// shutdownReaders shuts down readers that won't be used, ignoring their errors as there
+// is nothing left to export.
+func shutdownReaders(readers []metric.Reader) {
+	for _, reader := range readers {
+		_ = reader.Shutdown(context.Background())
+	}
 }
 
-//revive:disable:flag-parameter bools are fine for an internal function
-func newMetricsExporter(protocol Protocol, endpoint string, insecure bool, headers map[string]string) (metric.Exporter, error) {
-	switch protocol {
+func newMetricsExporter(c PipelineConfig) (metric.Exporter, error) {
+	switch c.Protocol {
 	case ProtocolGRPC:
-		return newGRPCMetricsExporter(endpoint, insecure, headers)
+		return newGRPCMetricsExporter(c)
 	case ProtocolHTTPProtobuf:
-		return newHTTPMetricsExporter(endpoint, insecure, headers)
+		return newHTTPMetricsExporter(c)
 	case ProtocolHTTPJSON:
-		return nil, errors.New("http/json is currently unsupported")
+		return newOTLPHTTPMetricsExporter(c)
 	default:
-		return nil, errors.New("'" + string(protocol) + "' is not a supported protocol")
+		return nil, errors.New("'" + string(c.Protocol) + "' is not a supported protocol")
 	}
 }
 
-func newGRPCMetricsExporter(endpoint string, insecure bool, headers map[string]string) (metric.Exporter, error) {
-	secureOption := otlpmetricgrpc.WithTLSCredentials(credentials.NewClientTLSFromCert(nil, ""))
-	if insecure {
+func newGRPCMetricsExporter(c PipelineConfig) (metric.Exporter, error) {
+	dialOptions, err := grpcDialOptions(c)
+	if err != nil {
+		return nil, err
+	}
+	secureOption := otlpmetricgrpc.WithTLSCredentials(grpcCredentials(c.TLSConfig))
+	if c.Insecure {
 		secureOption = otlpmetricgrpc.WithInsecure()
 	}
-	return otlpmetricgrpc.New(
-		context.Background(),
+	opts := []otlpmetricgrpc.Option{
 		secureOption,
-		otlpmetricgrpc.WithEndpoint(endpoint),
-		otlpmetricgrpc.WithHeaders(headers),
-		otlpmetricgrpc.WithCompressor(gzip.Name),
-	)
+		otlpmetricgrpc.WithEndpoint(c.Endpoint),
+		otlpmetricgrpc.WithHeaders(c.Headers),
+		otlpmetricgrpc.WithTimeout(exportTimeout(c.Timeout)),
+		otlpmetricgrpc.WithRetry(otlpmetricgrpc.RetryConfig(newRetryConfig(c))),
+		otlpmetricgrpc.WithDialOption(dialOptions...),
+	}
+	if c.GRPCConn != nil {
+		opts = append(opts, otlpmetricgrpc.WithGRPCConn(c.GRPCConn))
+	}
+	return otlpmetricgrpc.New(context.Background(), opts...)
 }
 
-func newHTTPMetricsExporter(endpoint string, insecure bool, headers map[string]string) (metric.Exporter, error) {
-	tlsconfig := &tls.Config{}
-	secureOption := otlpmetrichttp.WithTLSClientConfig(tlsconfig)
-	if insecure {
+func newHTTPMetricsExporter(c PipelineConfig) (metric.Exporter, error) {
+	if customHTTPTransport(c) {
+		return newOTLPHTTPMetricsExporter(c)
+	}
+	useGzip, err := httpGzip(c.Compression)
+	if err != nil {
+		return nil, err
+	}
+	compression := otlpmetrichttp.NoCompression
+	if useGzip {
+		compression = otlpmetrichttp.GzipCompression
+	}
+	secureOption := otlpmetrichttp.WithTLSClientConfig(httpTLSConfig(c.TLSConfig))
+	if c.Insecure {
 		secureOption = otlpmetrichttp.WithInsecure()
 	}
-	return otlpmetrichttp.New(
-		context.Background(),
+	opts := []otlpmetrichttp.Option{
 		secureOption,
-		otlpmetrichttp.WithEndpoint(endpoint),
-		otlpmetrichttp.WithHeaders(headers),
-		otlpmetrichttp.WithCompression(otlpmetrichttp.GzipCompression),
-	)
+		otlpmetrichttp.WithEndpoint(c.Endpoint),
+		otlpmetrichttp.WithHeaders(c.Headers),
+		otlpmetrichttp.WithTimeout(exportTimeout(c.Timeout)),
+		otlpmetrichttp.WithRetry(otlpmetrichttp.RetryConfig(newRetryConfig(c))),
+		otlpmetrichttp.WithCompression(compression),
+		otlpmetrichttp.WithProxy(proxyFromEnvironment),
+	}
+	if c.URLPath != "" {
+		opts = append(opts, otlpmetrichttp.WithURLPath(c.URLPath))
+	}
+	return otlpmetrichttp.New(context.Background(), opts...)
 }
@@ -0,0 +1,411 @@
+package pipelines
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	collectorlogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// These are the default URL paths for each signal, as defined by the OTLP/HTTP spec.
+const (
+	tracesURLPath  = "/v1/traces"
+	metricsURLPath = "/v1/metrics"
+	logsURLPath    = "/v1/logs"
+)
+
+var errExporterShutdown = errors.New("exporter is shut down")
+
+// protoExporter sends OTLP export requests.
+type protoExporter interface {
+	export(ctx context.Context, msg proto.Message) error
+	close()
+}
+
+// httpClient sends OTLP export requests to an OTLP/HTTP endpoint. It is used for the JSON
+// encoding, which the OTLP exporters don't support, and for protobuf when the requests
+// need a client or transport the OTLP exporters can't be given, such as for a Unix domain
+// socket, one set in PipelineConfig or one adding the headers of a HeaderProvider.
+type httpClient struct {
+	client      *http.Client
+	url         string
+	headers     map[string]string
+	gzip        bool
+	timeout     time.Duration
+	retry       retryConfig
+	contentType string
+	marshal     func(proto.Message) ([]byte, error)
+	// ownTransport is set when the transport was created for this client, and so can be
+	// cleaned up with it.
+	ownTransport bool
+}
+
+func newHTTPClient(c PipelineConfig, urlPath string) (*httpClient, error) {
+	useGzip, err := httpGzip(c.Compression)
+	if err != nil {
+		return nil, err
+	}
+	if c.URLPath != "" {
+		urlPath = c.URLPath
+	}
+	scheme := "https"
+	if c.Insecure {
+		scheme = "http"
+	}
+	client := &httpClient{
+		client:      c.HTTPClient,
+		url:         scheme + "://" + c.Endpoint + urlPath,
+		headers:     c.Headers,
+		gzip:        useGzip,
+		timeout:     exportTimeout(c.Timeout),
+		retry:       newRetryConfig(c),
+		contentType: "application/x-protobuf",
+		marshal:     proto.Marshal,
+	}
+	if c.Protocol == ProtocolHTTPJSON {
+		client.contentType = "application/json"
+		client.marshal = marshalOTLPJSON
+	}
+	if client.client == nil {
+		transport := c.HTTPTransport
+		if transport == nil {
+			transport = newHTTPTransport(c)
+			client.ownTransport = true
+		}
+		client.client = &http.Client{Transport: transport}
+	}
+	if c.HeaderProvider != nil {
+		if err := checkHeaderProvider(c); err != nil {
+			return nil, err
+		}
+		client.client = withHeaderTransport(client.client, c.HeaderProvider)
+	}
+	return client, nil
+}
+
+// newHTTPTransport returns a transport configured for the endpoint: with TLS unless the
+// endpoint is insecure, through the proxy from the environment, or over a socket.
+func newHTTPTransport(c PipelineConfig) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxyFromEnvironment
+	if !c.Insecure {
+		transport.TLSClientConfig = httpTLSConfig(c.TLSConfig)
+	}
+	if c.Socket != "" {
+		transport.Proxy = nil
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialSocket(ctx, c.Socket)
+		}
+	}
+	return transport
+}
+
+// customHTTPTransport reports whether the HTTP exporters need a client or transport the
+// OTLP exporters can't be given, so that httpClient has to be used instead.
+func customHTTPTransport(c PipelineConfig) bool {
+	return c.Socket != "" || c.HTTPClient != nil || c.HTTPTransport != nil || c.HeaderProvider != nil
+}
+
+// export marshals the request, optionally gzips it and posts it to the endpoint,
+// retrying with exponential backoff while the endpoint is unavailable. When the endpoint
+// says how long to wait with Retry-After, the next attempt waits at least that long.
+func (c *httpClient) export(ctx context.Context, msg proto.Message) error {
+	body, err := c.marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal OTLP request: %w", err)
+	}
+
+	if c.gzip {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err = gz.Write(body); err != nil {
+			return err
+		}
+		if err = gz.Close(); err != nil {
+			return err
+		}
+		body = buf.Bytes()
+	}
+
+	interval := c.retry.InitialInterval
+	deadline := time.Now().Add(c.retry.MaxElapsedTime)
+	for {
+		err = c.send(ctx, body)
+		var rErr retryableError
+		if err == nil || !c.retry.Enabled || !errors.As(err, &rErr) {
+			return err
+		}
+		wait := max(interval, rErr.retryAfter)
+		if time.Now().Add(wait).After(deadline) {
+			return fmt.Errorf("max retry time elapsed: %w", err)
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", ctx.Err(), err)
+		case <-time.After(wait):
+		}
+		interval = min(2*interval, c.retry.MaxInterval)
+	}
+}
+
+// send posts an encoded request to the endpoint once.
+func (c *httpClient) send(ctx context.Context, body []byte) error {
+	reqCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", c.contentType)
+	if c.gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		return retryableError{error: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		err := fmt.Errorf("failed to send to %s: %s: %s", c.url, resp.Status, bytes.TrimSpace(msg))
+		switch resp.StatusCode {
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+			return retryableError{error: err, retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+		case http.StatusBadGateway, http.StatusGatewayTimeout:
+			return retryableError{error: err}
+		}
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// retryableError is returned by send for failures that are worth retrying.
+type retryableError struct {
+	error
+	// retryAfter is how long the endpoint asked to wait before retrying, if it did.
+	retryAfter time.Duration
+}
+
+// parseRetryAfter parses a Retry-After header, which is either a number of seconds or an
+// HTTP date. It returns 0 if the header is missing or invalid.
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return max(time.Until(date), 0)
+	}
+	return 0
+}
+
+func (e retryableError) Unwrap() error {
+	return e.error
+}
+
+func (c *httpClient) close() {
+	if c.ownTransport {
+		c.client.CloseIdleConnections()
+	}
+}
+
+// marshalOTLPJSON encodes an OTLP message following the OTLP/JSON rules: enums are
+// written as integers, and trace and span IDs as hex strings rather than base64.
+func marshalOTLPJSON(msg proto.Message) ([]byte, error) {
+	b, err := protojson.MarshalOptions{UseEnumNumbers: true}.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if err := hexEncodeIDs(doc); err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// hexEncodeIDs rewrites the base64 encoded ID fields produced by protojson in place.
+func hexEncodeIDs(v interface{}) error {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			switch key {
+			case "traceId", "spanId", "parentSpanId":
+				if s, ok := value.(string); ok {
+					id, err := base64.StdEncoding.DecodeString(s)
+					if err != nil {
+						return fmt.Errorf("invalid %s %q: %w", key, s, err)
+					}
+					v[key] = hex.EncodeToString(id)
+				}
+			default:
+				if err := hexEncodeIDs(value); err != nil {
+					return err
+				}
+			}
+		}
+	case []interface{}:
+		for _, value := range v {
+			if err := hexEncodeIDs(value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// protoTraceClient implements otlptrace.Client on top of a protoExporter.
+type protoTraceClient struct {
+	protoExporter
+}
+
+var _ otlptrace.Client = protoTraceClient{}
+
+func (protoTraceClient) Start(context.Context) error {
+	return nil
+}
+
+func (c protoTraceClient) Stop(context.Context) error {
+	c.close()
+	return nil
+}
+
+func (c protoTraceClient) UploadTraces(ctx context.Context, spans []*tracepb.ResourceSpans) error {
+	return c.export(ctx, &collectortrace.ExportTraceServiceRequest{ResourceSpans: spans})
+}
+
+func newOTLPHTTPTraceClient(c PipelineConfig) (otlptrace.Client, error) {
+	client, err := newHTTPClient(c, tracesURLPath)
+	if err != nil {
+		return nil, err
+	}
+	return protoTraceClient{client}, nil
+}
+
+// protoMetricsExporter is a metric.Exporter on top of a protoExporter.
+type protoMetricsExporter struct {
+	client   protoExporter
+	shutdown atomic.Bool
+}
+
+var _ metric.Exporter = (*protoMetricsExporter)(nil)
+
+func newOTLPHTTPMetricsExporter(c PipelineConfig) (metric.Exporter, error) {
+	client, err := newHTTPClient(c, metricsURLPath)
+	if err != nil {
+		return nil, err
+	}
+	return &protoMetricsExporter{client: client}, nil
+}
+
+func (e *protoMetricsExporter) Temporality(kind metric.InstrumentKind) metricdata.Temporality {
+	return metric.DefaultTemporalitySelector(kind)
+}
+
+func (e *protoMetricsExporter) Aggregation(kind metric.InstrumentKind) metric.Aggregation {
+	return metric.DefaultAggregationSelector(kind)
+}
+
+func (e *protoMetricsExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	if e.shutdown.Load() {
+		return errExporterShutdown
+	}
+	pbMetrics, err := resourceMetricsToProto(rm)
+	if err != nil {
+		return err
+	}
+	return e.client.export(ctx, &collectormetrics.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{pbMetrics},
+	})
+}
+
+func (e *protoMetricsExporter) ForceFlush(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func (e *protoMetricsExporter) Shutdown(ctx context.Context) error {
+	if e.shutdown.Swap(true) {
+		return nil
+	}
+	e.client.close()
+	return ctx.Err()
+}
+
+// protoLogsExporter is a log.Exporter on top of a protoExporter.
+type protoLogsExporter struct {
+	client   protoExporter
+	shutdown atomic.Bool
+}
+
+var _ log.Exporter = (*protoLogsExporter)(nil)
+
+func newOTLPHTTPLogsExporter(c PipelineConfig) (log.Exporter, error) {
+	client, err := newHTTPClient(c, logsURLPath)
+	if err != nil {
+		return nil, err
+	}
+	return &protoLogsExporter{client: client}, nil
+}
+
+func (e *protoLogsExporter) Export(ctx context.Context, records []log.Record) error {
+	if e.shutdown.Load() {
+		return errExporterShutdown
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	return e.client.export(ctx, &collectorlogs.ExportLogsServiceRequest{
+		ResourceLogs: resourceLogsToProto(records),
+	})
+}
+
+func (e *protoLogsExporter) ForceFlush(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func (e *protoLogsExporter) Shutdown(ctx context.Context) error {
+	if e.shutdown.Swap(true) {
+		return nil
+	}
+	e.client.close()
+	return ctx.Err()
+}
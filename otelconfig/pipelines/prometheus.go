@@ -0,0 +1,64 @@
+package pipelines
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/prometheus"
+)
+
+// prometheusReader is a metric reader that collects metrics whenever Prometheus scrapes
+// /metrics, and stops serving them when it is shut down.
+type prometheusReader struct {
+	*prometheus.Exporter
+	server   *http.Server
+	listener net.Listener
+}
+
+// newPrometheusReader creates a reader and starts serving its metrics on the configured
+// host and port. Each reader has its own registry, so that several meter providers can be
+// scraped side by side.
+func newPrometheusReader(c PipelineConfig) (*prometheusReader, error) {
+	registry := prom.NewRegistry()
+	exporter, err := prometheus.New(prometheus.WithRegisterer(registry))
+	if err != nil {
+		return nil, err
+	}
+
+	addr := net.JoinHostPort(c.PrometheusHost, strconv.Itoa(c.PrometheusPort))
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		_ = exporter.Shutdown(context.Background())
+		return nil, fmt.Errorf("failed to serve Prometheus metrics: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			otel.Handle(fmt.Errorf("failed to serve Prometheus metrics: %w", err))
+		}
+	}()
+
+	return &prometheusReader{Exporter: exporter, server: server, listener: listener}, nil
+}
+
+// Shutdown stops serving metrics and shuts down the exporter. The server only closes the
+// listener once it is serving, so it is closed here too in case it isn't yet.
+func (r *prometheusReader) Shutdown(ctx context.Context) error {
+	err := r.server.Shutdown(ctx)
+	if lErr := r.listener.Close(); lErr != nil && !errors.Is(lErr, net.ErrClosed) {
+		err = errors.Join(err, lErr)
+	}
+	return errors.Join(err, r.Exporter.Shutdown(ctx))
+}
@@ -0,0 +1,345 @@
+package pipelines
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// queueFileExt is the extension of the files holding spooled batches; their names are
+// zero-padded sequence numbers, so listing the directory returns them in order.
+const queueFileExt = ".pb"
+
+// persistentTraceClient is an otlptrace.Client that spools batches it fails to upload to a
+// directory, and replays them in order once the endpoint is reachable again. Batches left
+// over from a previous run are replayed too. Only failures worth retrying are spooled;
+// batches the endpoint rejects are dropped.
+type persistentTraceClient struct {
+	client   otlptrace.Client
+	dir      string
+	maxBytes int64
+	interval time.Duration
+	queue    *traceQueue
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+var _ otlptrace.Client = (*persistentTraceClient)(nil)
+
+// traceQueue indexes the batches spooled to a directory, so that the directory is only
+// listed once. It is shared by all clients using the same directory, as an exporter that
+// is being replaced by a reload may still be sending.
+type traceQueue struct {
+	// mu guards the index; it isn't held while uploading.
+	mu     sync.Mutex
+	loaded bool
+	files  []queueFile // oldest first
+	size   int64
+	// nextSeq numbers batches in the order they are exported, so that a batch that fails
+	// to send goes ahead of those spooled while it was being sent.
+	nextSeq uint64
+	// sending is set while a batch is being uploaded. Batches exported meanwhile are
+	// spooled, so that they are never sent ahead of it.
+	sending bool
+}
+
+// traceQueues holds the traceQueue of each queue directory.
+var traceQueues sync.Map
+
+func newPersistentTraceClient(client otlptrace.Client, c PipelineConfig) *persistentTraceClient {
+	return &persistentTraceClient{
+		client:   client,
+		dir:      c.QueueDir,
+		maxBytes: c.QueueMaxBytes,
+		interval: newRetryConfig(c).InitialInterval,
+		queue:    queueFor(c.QueueDir),
+		done:     make(chan struct{}),
+	}
+}
+
+func queueFor(dir string) *traceQueue {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	queue, _ := traceQueues.LoadOrStore(dir, &traceQueue{})
+	return queue.(*traceQueue)
+}
+
+type queueFile struct {
+	name string
+	size int64
+}
+
+func (q *persistentTraceClient) Start(ctx context.Context) error {
+	if err := os.MkdirAll(q.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create queue directory: %w", err)
+	}
+	if err := q.load(); err != nil {
+		return err
+	}
+	if err := q.client.Start(ctx); err != nil {
+		return err
+	}
+
+	q.ctx, q.cancel = context.WithCancel(context.Background())
+	go q.run()
+	return nil
+}
+
+// load indexes the batches left over from a previous run, unless another client using the
+// same directory already has.
+func (q *persistentTraceClient) load() error {
+	q.queue.mu.Lock()
+	defer q.queue.mu.Unlock()
+	if q.queue.loaded {
+		return nil
+	}
+	files, err := readQueueDir(q.dir)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		q.queue.size += f.size
+	}
+	q.queue.files = files
+	q.queue.nextSeq = nextQueueSeq(files)
+	q.queue.loaded = true
+	return nil
+}
+
+// Stop stops replaying and stops the underlying client. Batches that are still spooled
+// stay on disk, to be replayed the next time the queue is started.
+func (q *persistentTraceClient) Stop(ctx context.Context) error {
+	q.cancel()
+	select {
+	case <-q.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return q.client.Stop(ctx)
+}
+
+// UploadTraces sends the spans straight away when nothing is spooled or being sent, and
+// spools them otherwise or when sending fails with an error worth retrying. Spooled spans
+// count as exported.
+func (q *persistentTraceClient) UploadTraces(ctx context.Context, spans []*tracepb.ResourceSpans) error {
+	q.queue.mu.Lock()
+	seq := q.queue.nextSeq
+	q.queue.nextSeq++
+	direct := len(q.queue.files) == 0 && !q.queue.sending
+	q.queue.sending = q.queue.sending || direct
+	q.queue.mu.Unlock()
+
+	req := &collectortrace.ExportTraceServiceRequest{ResourceSpans: spans}
+	if !direct {
+		return q.spool(seq, req)
+	}
+	err := q.client.UploadTraces(ctx, spans)
+	if err != nil && retryable(err) {
+		otel.Handle(fmt.Errorf("failed to export spans, spooling them to %s: %w", q.dir, err))
+		err = q.spool(seq, req)
+	}
+	q.queue.mu.Lock()
+	q.queue.sending = false
+	q.queue.mu.Unlock()
+	return err
+}
+
+// spool writes a request to the queue in the position of seq, dropping the oldest batches
+// if it would grow beyond its maximum size.
+func (q *persistentTraceClient) spool(seq uint64, req *collectortrace.ExportTraceServiceRequest) error {
+	data, err := proto.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal spans: %w", err)
+	}
+
+	q.queue.mu.Lock()
+	defer q.queue.mu.Unlock()
+	if q.maxBytes > 0 {
+		if int64(len(data)) > q.maxBytes {
+			return fmt.Errorf("dropped a batch of %d bytes, larger than the queue", len(data))
+		}
+		dropped := 0
+		for ; q.queue.size+int64(len(data)) > q.maxBytes; dropped++ {
+			if err := q.remove(q.queue.files[0]); err != nil {
+				return err
+			}
+		}
+		if dropped > 0 {
+			otel.Handle(fmt.Errorf("queue is full, dropped the %d oldest batches", dropped))
+		}
+	}
+
+	// write to a temporary file first, so that a crash never leaves a partial batch behind
+	name := fmt.Sprintf("%020d%s", seq, queueFileExt)
+	path := filepath.Join(q.dir, name)
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return fmt.Errorf("failed to spool spans: %w", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("failed to spool spans: %w", err)
+	}
+	// the names sort like the sequence numbers, as they are zero-padded
+	i := sort.Search(len(q.queue.files), func(i int) bool { return q.queue.files[i].name > name })
+	q.queue.files = slices.Insert(q.queue.files, i, queueFile{name: name, size: int64(len(data))})
+	q.queue.size += int64(len(data))
+	return nil
+}
+
+// remove removes a spooled batch from disk and from the index, unless it has already been
+// removed. q.queue.mu must be held.
+func (q *persistentTraceClient) remove(f queueFile) error {
+	i := slices.Index(q.queue.files, f)
+	if i < 0 {
+		return nil
+	}
+	if err := os.Remove(filepath.Join(q.dir, f.name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	q.queue.files = slices.Delete(q.queue.files, i, i+1)
+	q.queue.size -= f.size
+	return nil
+}
+
+func (q *persistentTraceClient) run() {
+	defer close(q.done)
+	ticker := time.NewTicker(q.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-q.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := q.replay(); err != nil {
+				otel.Handle(err)
+			}
+		}
+	}
+}
+
+// replay uploads spooled batches oldest first, stopping at the first failure worth
+// retrying. Batches the endpoint rejects are dropped, so that they don't hold up the ones
+// behind them. It leaves the queue alone while another batch is being sent.
+func (q *persistentTraceClient) replay() error {
+	for {
+		q.queue.mu.Lock()
+		if q.queue.sending || len(q.queue.files) == 0 {
+			q.queue.mu.Unlock()
+			return nil
+		}
+		f := q.queue.files[0]
+		q.queue.sending = true
+		q.queue.mu.Unlock()
+
+		keep, err := q.replayFile(f)
+
+		q.queue.mu.Lock()
+		q.queue.sending = false
+		if err == nil && !keep {
+			err = q.remove(f)
+		}
+		q.queue.mu.Unlock()
+		if err != nil || keep {
+			return err
+		}
+	}
+}
+
+// replayFile uploads a spooled batch, and reports whether it has to stay in the queue.
+func (q *persistentTraceClient) replayFile(f queueFile) (bool, error) {
+	path := filepath.Join(q.dir, f.name)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		otel.Handle(fmt.Errorf("spooled batch %s has disappeared", path))
+		return false, nil
+	} else if err != nil {
+		return true, err
+	}
+	return q.keep(path, data), nil
+}
+
+// keep uploads a spooled batch, and reports whether it has to stay in the queue because
+// uploading it failed with an error worth retrying.
+func (q *persistentTraceClient) keep(path string, data []byte) bool {
+	req := &collectortrace.ExportTraceServiceRequest{}
+	if err := proto.Unmarshal(data, req); err != nil {
+		otel.Handle(fmt.Errorf("dropped corrupt spooled batch %s: %w", path, err))
+		return false
+	}
+	err := q.client.UploadTraces(q.ctx, req.ResourceSpans)
+	if err == nil {
+		return false
+	}
+	if retryable(err) || q.ctx.Err() != nil {
+		// the endpoint is most likely still down, or the queue is being stopped
+		return true
+	}
+	otel.Handle(fmt.Errorf("dropped spooled batch %s: %w", path, err))
+	return false
+}
+
+// retryable reports whether an upload failed for a reason that may go away, such as the
+// endpoint being unreachable or overloaded, rather than because it rejected the spans.
+func retryable(err error) bool {
+	var rErr retryableError
+	if errors.As(err, &rErr) {
+		return true
+	}
+	if s, ok := status.FromError(err); ok {
+		switch s.Code() {
+		case codes.Unavailable, codes.ResourceExhausted, codes.DeadlineExceeded:
+			return true
+		}
+	}
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// nextQueueSeq returns the sequence number for a batch spooled after files.
+func nextQueueSeq(files []queueFile) uint64 {
+	if len(files) == 0 {
+		return 0
+	}
+	last, _ := strconv.ParseUint(strings.TrimSuffix(files[len(files)-1].name, queueFileExt), 10, 64)
+	return last + 1
+}
+
+// readQueueDir lists the batches spooled to dir, oldest first.
+func readQueueDir(dir string) ([]queueFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue directory: %w", err)
+	}
+	var files []queueFile
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != queueFileExt {
+			continue
+		}
+		info, err := entry.Info()
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		files = append(files, queueFile{name: entry.Name(), size: info.Size()})
+	}
+	return files, nil
+}
@@ -0,0 +1,221 @@
+package pipelines
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Reloader rebuilds the OTLP exporters of a pipeline with new settings, such as endpoints
+// and headers, while the pipeline is running. Telemetry that is buffered by the processors
+// is kept and sent with the new exporters. Which exporters are used can't be changed.
+type Reloader struct {
+	mu      sync.Mutex
+	reloads []func(PipelineConfig) error
+}
+
+// Reload rebuilds the exporters registered with r from c, swapping each one in once it has
+// been built. An exporter that fails to build is left as it was.
+func (r *Reloader) Reload(c PipelineConfig) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var errs []error
+	for _, reload := range r.reloads {
+		if err := reload(c); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Reloader) register(reload func(PipelineConfig) error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reloads = append(r.reloads, reload)
+}
+
+// exporter is what the trace, metric and log exporters have in common.
+type exporter interface {
+	Shutdown(ctx context.Context) error
+}
+
+// swappable holds an exporter that can be replaced. Neither exports nor swaps wait for
+// each other: exports use whichever exporter is current when they start, and a replaced
+// exporter is shut down in the background once the exports still using it have finished.
+type swappable[T exporter] struct {
+	current atomic.Pointer[exporterSlot[T]]
+
+	// mu serializes swaps with Shutdown; exports don't take it.
+	mu       sync.Mutex
+	shutdown bool
+	retiring sync.WaitGroup
+}
+
+// exporterSlot is an exporter and the exports in flight on it, each holding a read lock.
+type exporterSlot[T exporter] struct {
+	exporter T
+	mu       sync.RWMutex
+	retired  bool
+}
+
+// acquire returns the current exporter, read locked until the export using it is done.
+func (s *swappable[T]) acquire() *exporterSlot[T] {
+	for {
+		slot := s.current.Load()
+		// the lock is only taken once a swap has replaced slot, so rather than waiting
+		// for it, the export moves on to the new one
+		if slot.mu.TryRLock() {
+			if !slot.retired {
+				return slot
+			}
+			slot.mu.RUnlock()
+		}
+	}
+}
+
+// swap replaces the current exporter with next. The previous one is shut down once its
+// in-flight exports have finished, and an error doing so goes to the error handler.
+func (s *swappable[T]) swap(next T) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.shutdown {
+		// the pipeline has gone, so next is never used
+		return next.Shutdown(context.Background())
+	}
+	prev := s.current.Swap(&exporterSlot[T]{exporter: next})
+	s.retiring.Add(1)
+	go func() {
+		defer s.retiring.Done()
+		prev.mu.Lock()
+		prev.retired = true
+		prev.mu.Unlock()
+		if err := prev.exporter.Shutdown(context.Background()); err != nil {
+			otel.Handle(err)
+		}
+	}()
+	return nil
+}
+
+// Shutdown shuts down the current exporter, and waits for the replaced ones to be shut
+// down too.
+func (s *swappable[T]) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.shutdown {
+		return nil
+	}
+	s.shutdown = true
+	err := s.current.Load().exporter.Shutdown(ctx)
+
+	retired := make(chan struct{})
+	go func() {
+		s.retiring.Wait()
+		close(retired)
+	}()
+	select {
+	case <-retired:
+	case <-ctx.Done():
+		err = errors.Join(err, ctx.Err())
+	}
+	return err
+}
+
+// newSwappable wraps an exporter so that r can replace it with one built by newExporter.
+func newSwappable[T exporter](r *Reloader, current T, newExporter func(PipelineConfig) (T, error)) *swappable[T] {
+	s := &swappable[T]{}
+	s.current.Store(&exporterSlot[T]{exporter: current})
+	r.register(func(c PipelineConfig) error {
+		next, err := newExporter(c)
+		if err != nil {
+			return err
+		}
+		return s.swap(next)
+	})
+	return s
+}
+
+// reloadableSpanExporter lets c.Reloader replace e, if there is one.
+func reloadableSpanExporter(c PipelineConfig, e trace.SpanExporter) trace.SpanExporter {
+	if c.Reloader == nil {
+		return e
+	}
+	return swappableSpanExporter{newSwappable(c.Reloader, e, func(c PipelineConfig) (trace.SpanExporter, error) {
+		return newTraceExporter(c)
+	})}
+}
+
+// reloadableMetricsExporter lets c.Reloader replace e, if there is one.
+func reloadableMetricsExporter(c PipelineConfig, e metric.Exporter) metric.Exporter {
+	if c.Reloader == nil {
+		return e
+	}
+	return swappableMetricsExporter{newSwappable(c.Reloader, e, newMetricsExporter)}
+}
+
+// reloadableLogsExporter lets c.Reloader replace e, if there is one.
+func reloadableLogsExporter(c PipelineConfig, e log.Exporter) log.Exporter {
+	if c.Reloader == nil {
+		return e
+	}
+	return swappableLogsExporter{newSwappable(c.Reloader, e, newLogsExporter)}
+}
+
+type swappableSpanExporter struct {
+	*swappable[trace.SpanExporter]
+}
+
+func (s swappableSpanExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	slot := s.acquire()
+	defer slot.mu.RUnlock()
+	return slot.exporter.ExportSpans(ctx, spans)
+}
+
+type swappableMetricsExporter struct {
+	*swappable[metric.Exporter]
+}
+
+func (s swappableMetricsExporter) Temporality(kind metric.InstrumentKind) metricdata.Temporality {
+	return s.current.Load().exporter.Temporality(kind)
+}
+
+func (s swappableMetricsExporter) Aggregation(kind metric.InstrumentKind) metric.Aggregation {
+	return s.current.Load().exporter.Aggregation(kind)
+}
+
+func (s swappableMetricsExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	slot := s.acquire()
+	defer slot.mu.RUnlock()
+	return slot.exporter.Export(ctx, rm)
+}
+
+func (s swappableMetricsExporter) ForceFlush(ctx context.Context) error {
+	slot := s.acquire()
+	defer slot.mu.RUnlock()
+	return slot.exporter.ForceFlush(ctx)
+}
+
+type swappableLogsExporter struct {
+	*swappable[log.Exporter]
+}
+
+func (s swappableLogsExporter) Export(ctx context.Context, records []log.Record) error {
+	slot := s.acquire()
+	defer slot.mu.RUnlock()
+	return slot.exporter.Export(ctx, records)
+}
+
+func (s swappableLogsExporter) ForceFlush(ctx context.Context) error {
+	slot := s.acquire()
+	defer slot.mu.RUnlock()
+	return slot.exporter.ForceFlush(ctx)
+}
@@ -2,26 +2,48 @@ package pipelines
 
 import (
 	"context"
-	"crypto/tls"
 	"errors"
 	"fmt"
 
-	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/encoding/gzip"
-
 	"go.opentelemetry.io/contrib/propagators/b3"
 	"go.opentelemetry.io/contrib/propagators/ot"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/trace"
 )
 
-// NewTracePipeline creates a new trace pipeline from a config.
+// NewTracePipeline creates a new trace pipeline from a config, and registers its
+// TracerProvider and propagators globally.
 // It returns a shutdown function that should be called when terminating the pipeline.
 func NewTracePipeline(c PipelineConfig) (func() error, error) {
+	return withoutContext(NewTracePipelineWithContext(c))
+}
+
+// NewTracePipelineWithContext is like NewTracePipeline, but the context passed to the shutdown
+// function bounds how long the final export may take.
+func NewTracePipelineWithContext(c PipelineConfig) (func(context.Context) error, error) {
+	propagator, err := NewPropagator(c.Propagators)
+	if err != nil {
+		return nil, err
+	}
+
+	tp, err := NewTracerProvider(c)
+	if err != nil {
+		return nil, err
+	}
+
+	otel.SetTextMapPropagator(propagator)
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// NewTracerProvider creates a TracerProvider that exports spans as configured,
+// without registering it globally.
+func NewTracerProvider(c PipelineConfig) (*trace.TracerProvider, error) {
 	opts := []trace.TracerProviderOption{
 		trace.WithResource(c.Resource),
 		trace.WithSampler(c.Sampler),
@@ -30,77 +52,183 @@ func NewTracePipeline(c PipelineConfig) (func() error, error) {
 		opts = append(opts, trace.WithSpanProcessor(sp))
 	}
 
-	// make sure the exporter is added last
-	spanExporter, err := newTraceExporter(c.Protocol, c.Endpoint, c.Insecure, c.Headers)
+	bspOpts, err := batchSpanProcessorOptions(c)
+	if err != nil {
+		return nil, err
+	}
+
+	// make sure the exporters are added last
+	spanExporters, err := newTraceExporters(c)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create span exporter: %v", err)
 	}
+	for _, spanExporter := range spanExporters {
+		opts = append(opts, trace.WithSpanProcessor(trace.NewBatchSpanProcessor(spanExporter, bspOpts...)))
+	}
 
-	bsp := trace.NewBatchSpanProcessor(spanExporter)
-	opts = append(opts, trace.WithSpanProcessor(bsp))
+	return trace.NewTracerProvider(opts...), nil
+}
 
-	tp := trace.NewTracerProvider(opts...)
-	if err = configurePropagators(c); err != nil {
-		return nil, err
+// batchSpanProcessorOptions converts the batch span processor settings that were
+// configured into options, leaving the rest at the SDK defaults.
+func batchSpanProcessorOptions(c PipelineConfig) ([]trace.BatchSpanProcessorOption, error) {
+	if c.BSPScheduleDelay < 0 || c.BSPExportTimeout < 0 ||
+		c.BSPMaxQueueSize < 0 || c.BSPMaxExportBatchSize < 0 {
+		return nil, fmt.Errorf("invalid batch span processor configuration: values must not be negative")
 	}
 
-	otel.SetTracerProvider(tp)
+	var opts []trace.BatchSpanProcessorOption
+	if c.BSPScheduleDelay > 0 {
+		opts = append(opts, trace.WithBatchTimeout(c.BSPScheduleDelay))
+	}
+	if c.BSPExportTimeout > 0 {
+		opts = append(opts, trace.WithExportTimeout(c.BSPExportTimeout))
+	}
+	if c.BSPMaxQueueSize > 0 {
+		opts = append(opts, trace.WithMaxQueueSize(c.BSPMaxQueueSize))
+	}
+	if c.BSPMaxExportBatchSize > 0 {
+		opts = append(opts, trace.WithMaxExportBatchSize(c.BSPMaxExportBatchSize))
+	}
+	return opts, nil
+}
 
-	return func() error {
-		_ = bsp.Shutdown(context.Background())
-		return spanExporter.Shutdown(context.Background())
-	}, nil
+// newTraceExporters creates an exporter for each configured name. If one of them can't be
+// created, those already created are shut down, so that their connections and files are
+// released.
+func newTraceExporters(c PipelineConfig) ([]trace.SpanExporter, error) {
+	var exporters []trace.SpanExporter
+	for _, name := range exporterNames(c.Exporters) {
+		var exporter trace.SpanExporter
+		var err error
+		switch name {
+		case ExporterOTLP:
+			var otlpExporter *otlptrace.Exporter
+			otlpExporter, err = newTraceExporter(c)
+			if err == nil {
+				exporter = reloadableSpanExporter(c, otlpExporter)
+			}
+		case ExporterConsole:
+			exporter, err = stdouttrace.New(stdouttrace.WithWriter(consoleWriter(c)), stdouttrace.WithPrettyPrint())
+		case ExporterFile:
+			exporter, err = newFileTraceExporter(c)
+		default:
+			err = fmt.Errorf("unsupported traces exporter %q", name)
+		}
+		if err != nil {
+			shutdownSpanExporters(exporters)
+			return nil, err
+		}
+		exporters = append(exporters, exporter)
+	}
+	return exporters, nil
 }
 
-//revive:disable:flag-parameter bools are fine for an internal function
-func newTraceExporter(protocol Protocol, endpoint string, insecure bool, headers map[string]string) (*otlptrace.Exporter, error) {
-	switch protocol {
+// shutdownSpanExporters shuts down exporters that won't be used, ignoring their errors as
+// there is nothing left to export.
+func shutdownSpanExporters(exporters []trace.SpanExporter) {
+	for _, exporter := range exporters {
+		_ = exporter.Shutdown(context.Background())
+	}
+}
+
+func newTraceExporter(c PipelineConfig) (*otlptrace.Exporter, error) {
+	if c.QueueDir == "" {
+		client, err := newTraceClient(c)
+		if err != nil {
+			return nil, err
+		}
+		return otlptrace.New(context.Background(), client)
+	}
+
+	// the queue takes over retrying, so that failed batches are spooled straight away
+	// rather than holding up the batch span processor
+	clientConfig := c
+	clientConfig.RetryDisabled = true
+	var client otlptrace.Client
+	var err error
+	if c.Protocol == ProtocolHTTPProtobuf {
+		// the queue needs to tell the failures worth retrying apart, which the errors of
+		// the otlptracehttp client don't let it do
+		client, err = newOTLPHTTPTraceClient(clientConfig)
+	} else {
+		client, err = newTraceClient(clientConfig)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return otlptrace.New(context.Background(), newPersistentTraceClient(client, c))
+}
+
+func newTraceClient(c PipelineConfig) (otlptrace.Client, error) {
+	switch c.Protocol {
 	case ProtocolGRPC:
-		return newGRPCTraceExporter(endpoint, insecure, headers)
+		return newGRPCTraceClient(c)
 	case ProtocolHTTPProtobuf:
-		return newHTTPTraceExporter(endpoint, insecure, headers)
+		return newHTTPTraceClient(c)
 	case ProtocolHTTPJSON:
-		return nil, errors.New("http/json is currently unsupported")
+		return newOTLPHTTPTraceClient(c)
 	default:
-		return nil, errors.New("'" + string(protocol) + "' is not a supported protocol")
+		return nil, errors.New("'" + string(c.Protocol) + "' is not a supported protocol")
 	}
 }
 
-func newGRPCTraceExporter(endpoint string, insecure bool, headers map[string]string) (*otlptrace.Exporter, error) {
-	secureOption := otlptracegrpc.WithTLSCredentials(credentials.NewClientTLSFromCert(nil, ""))
-	if insecure {
+func newGRPCTraceClient(c PipelineConfig) (otlptrace.Client, error) {
+	dialOptions, err := grpcDialOptions(c)
+	if err != nil {
+		return nil, err
+	}
+	secureOption := otlptracegrpc.WithTLSCredentials(grpcCredentials(c.TLSConfig))
+	if c.Insecure {
 		secureOption = otlptracegrpc.WithInsecure()
 	}
-	return otlptrace.New(
-		context.Background(),
-		otlptracegrpc.NewClient(
-			secureOption,
-			otlptracegrpc.WithEndpoint(endpoint),
-			otlptracegrpc.WithHeaders(headers),
-			otlptracegrpc.WithCompressor(gzip.Name),
-		),
-	)
+	opts := []otlptracegrpc.Option{
+		secureOption,
+		otlptracegrpc.WithEndpoint(c.Endpoint),
+		otlptracegrpc.WithHeaders(c.Headers),
+		otlptracegrpc.WithTimeout(exportTimeout(c.Timeout)),
+		otlptracegrpc.WithRetry(otlptracegrpc.RetryConfig(newRetryConfig(c))),
+		otlptracegrpc.WithDialOption(dialOptions...),
+	}
+	if c.GRPCConn != nil {
+		opts = append(opts, otlptracegrpc.WithGRPCConn(c.GRPCConn))
+	}
+	return otlptracegrpc.NewClient(opts...), nil
 }
 
-func newHTTPTraceExporter(endpoint string, insecure bool, headers map[string]string) (*otlptrace.Exporter, error) {
-	tlsconfig := &tls.Config{}
-	secureOption := otlptracehttp.WithTLSClientConfig(tlsconfig)
-	if insecure {
+func newHTTPTraceClient(c PipelineConfig) (otlptrace.Client, error) {
+	if customHTTPTransport(c) {
+		return newOTLPHTTPTraceClient(c)
+	}
+	useGzip, err := httpGzip(c.Compression)
+	if err != nil {
+		return nil, err
+	}
+	compression := otlptracehttp.NoCompression
+	if useGzip {
+		compression = otlptracehttp.GzipCompression
+	}
+	secureOption := otlptracehttp.WithTLSClientConfig(httpTLSConfig(c.TLSConfig))
+	if c.Insecure {
 		secureOption = otlptracehttp.WithInsecure()
 	}
-	return otlptrace.New(
-		context.Background(),
-		otlptracehttp.NewClient(
-			secureOption,
-			otlptracehttp.WithEndpoint(endpoint),
-			otlptracehttp.WithHeaders(headers),
-			otlptracehttp.WithCompression(otlptracehttp.GzipCompression),
-		),
-	)
+	opts := []otlptracehttp.Option{
+		secureOption,
+		otlptracehttp.WithEndpoint(c.Endpoint),
+		otlptracehttp.WithHeaders(c.Headers),
+		otlptracehttp.WithTimeout(exportTimeout(c.Timeout)),
+		otlptracehttp.WithRetry(otlptracehttp.RetryConfig(newRetryConfig(c))),
+		otlptracehttp.WithCompression(compression),
+		otlptracehttp.WithProxy(proxyFromEnvironment),
+	}
+	if c.URLPath != "" {
+		opts = append(opts, otlptracehttp.WithURLPath(c.URLPath))
+	}
+	return otlptracehttp.NewClient(opts...), nil
 }
 
-// configurePropagators configures B3 propagation by default.
-func configurePropagators(c PipelineConfig) error {
+// NewPropagator builds a composite propagator from a list of propagator names.
+func NewPropagator(names []string) (propagation.TextMapPropagator, error) {
 	propagatorsMap := map[string]propagation.TextMapPropagator{
 		"b3":           b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)),
 		"baggage":      propagation.Baggage{},
@@ -108,17 +236,14 @@ func configurePropagators(c PipelineConfig) error {
 		"ottrace":      ot.OT{},
 	}
 	var props []propagation.TextMapPropagator
-	for _, key := range c.Propagators {
+	for _, key := range names {
 		prop := propagatorsMap[key]
 		if prop != nil {
 			props = append(props, prop)
 		}
 	}
 	if len(props) == 0 {
-		return fmt.Errorf("invalid configuration: unsupported propagators. Supported options: b3,baggage,tracecontext,ottrace")
+		return nil, fmt.Errorf("invalid configuration: unsupported propagators. Supported options: b3,baggage,tracecontext,ottrace")
 	}
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
-		props...,
-	))
-	return nil
+	return propagation.NewCompositeTextMapPropagator(props...), nil
 }
@@ -0,0 +1,386 @@
+package pipelines
+
+import (
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+)
+
+// The OTLP exporters keep their SDK to protobuf conversions internal, so the
+// exporters in this package that write OTLP protos themselves use these instead.
+
+// resourceMetricsToProto converts SDK metric data into its OTLP protobuf representation.
+func resourceMetricsToProto(rm *metricdata.ResourceMetrics) (*metricspb.ResourceMetrics, error) {
+	out := &metricspb.ResourceMetrics{
+		Resource:  resourceToProto(rm.Resource),
+		SchemaUrl: rm.Resource.SchemaURL(),
+	}
+	for _, sm := range rm.ScopeMetrics {
+		scopeMetrics := &metricspb.ScopeMetrics{
+			Scope:     scopeToProto(sm.Scope),
+			SchemaUrl: sm.Scope.SchemaURL,
+		}
+		for _, m := range sm.Metrics {
+			metric, err := metricToProto(m)
+			if err != nil {
+				return nil, err
+			}
+			scopeMetrics.Metrics = append(scopeMetrics.Metrics, metric)
+		}
+		out.ScopeMetrics = append(out.ScopeMetrics, scopeMetrics)
+	}
+	return out, nil
+}
+
+func metricToProto(m metricdata.Metrics) (*metricspb.Metric, error) {
+	out := &metricspb.Metric{
+		Name:        m.Name,
+		Description: m.Description,
+		Unit:        m.Unit,
+	}
+	switch data := m.Data.(type) {
+	case metricdata.Gauge[int64]:
+		out.Data = &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: numberDataPointsToProto(data.DataPoints)}}
+	case metricdata.Gauge[float64]:
+		out.Data = &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: numberDataPointsToProto(data.DataPoints)}}
+	case metricdata.Sum[int64]:
+		out.Data = &metricspb.Metric_Sum{Sum: sumToProto(data)}
+	case metricdata.Sum[float64]:
+		out.Data = &metricspb.Metric_Sum{Sum: sumToProto(data)}
+	case metricdata.Histogram[int64]:
+		out.Data = &metricspb.Metric_Histogram{Histogram: histogramToProto(data)}
+	case metricdata.Histogram[float64]:
+		out.Data = &metricspb.Metric_Histogram{Histogram: histogramToProto(data)}
+	case metricdata.ExponentialHistogram[int64]:
+		out.Data = &metricspb.Metric_ExponentialHistogram{ExponentialHistogram: exponentialHistogramToProto(data)}
+	case metricdata.ExponentialHistogram[float64]:
+		out.Data = &metricspb.Metric_ExponentialHistogram{ExponentialHistogram: exponentialHistogramToProto(data)}
+	case metricdata.Summary:
+		out.Data = &metricspb.Metric_Summary{Summary: summaryToProto(data)}
+	default:
+		return nil, fmt.Errorf("unsupported metric aggregation for %q: %T", m.Name, m.Data)
+	}
+	return out, nil
+}
+
+func sumToProto[N int64 | float64](s metricdata.Sum[N]) *metricspb.Sum {
+	return &metricspb.Sum{
+		DataPoints:             numberDataPointsToProto(s.DataPoints),
+		AggregationTemporality: temporalityToProto(s.Temporality),
+		IsMonotonic:            s.IsMonotonic,
+	}
+}
+
+func numberDataPointsToProto[N int64 | float64](dps []metricdata.DataPoint[N]) []*metricspb.NumberDataPoint {
+	out := make([]*metricspb.NumberDataPoint, 0, len(dps))
+	for _, dp := range dps {
+		pt := &metricspb.NumberDataPoint{
+			Attributes:        attributesToProto(dp.Attributes.ToSlice()),
+			StartTimeUnixNano: timeToProto(dp.StartTime),
+			TimeUnixNano:      timeToProto(dp.Time),
+			Exemplars:         exemplarsToProto(dp.Exemplars),
+		}
+		switch v := any(dp.Value).(type) {
+		case int64:
+			pt.Value = &metricspb.NumberDataPoint_AsInt{AsInt: v}
+		case float64:
+			pt.Value = &metricspb.NumberDataPoint_AsDouble{AsDouble: v}
+		}
+		out = append(out, pt)
+	}
+	return out
+}
+
+func histogramToProto[N int64 | float64](h metricdata.Histogram[N]) *metricspb.Histogram {
+	out := &metricspb.Histogram{AggregationTemporality: temporalityToProto(h.Temporality)}
+	for _, dp := range h.DataPoints {
+		sum := float64(dp.Sum)
+		out.DataPoints = append(out.DataPoints, &metricspb.HistogramDataPoint{
+			Attributes:        attributesToProto(dp.Attributes.ToSlice()),
+			StartTimeUnixNano: timeToProto(dp.StartTime),
+			TimeUnixNano:      timeToProto(dp.Time),
+			Count:             dp.Count,
+			Sum:               &sum,
+			BucketCounts:      dp.BucketCounts,
+			ExplicitBounds:    dp.Bounds,
+			Min:               extremaToProto(dp.Min),
+			Max:               extremaToProto(dp.Max),
+			Exemplars:         exemplarsToProto(dp.Exemplars),
+		})
+	}
+	return out
+}
+
+func exponentialHistogramToProto[N int64 | float64](h metricdata.ExponentialHistogram[N]) *metricspb.ExponentialHistogram {
+	out := &metricspb.ExponentialHistogram{AggregationTemporality: temporalityToProto(h.Temporality)}
+	for _, dp := range h.DataPoints {
+		sum := float64(dp.Sum)
+		out.DataPoints = append(out.DataPoints, &metricspb.ExponentialHistogramDataPoint{
+			Attributes:        attributesToProto(dp.Attributes.ToSlice()),
+			StartTimeUnixNano: timeToProto(dp.StartTime),
+			TimeUnixNano:      timeToProto(dp.Time),
+			Count:             dp.Count,
+			Sum:               &sum,
+			Scale:             dp.Scale,
+			ZeroCount:         dp.ZeroCount,
+			ZeroThreshold:     dp.ZeroThreshold,
+			Positive: &metricspb.ExponentialHistogramDataPoint_Buckets{
+				Offset:       dp.PositiveBucket.Offset,
+				BucketCounts: dp.PositiveBucket.Counts,
+			},
+			Negative: &metricspb.ExponentialHistogramDataPoint_Buckets{
+				Offset:       dp.NegativeBucket.Offset,
+				BucketCounts: dp.NegativeBucket.Counts,
+			},
+			Min:       extremaToProto(dp.Min),
+			Max:       extremaToProto(dp.Max),
+			Exemplars: exemplarsToProto(dp.Exemplars),
+		})
+	}
+	return out
+}
+
+func summaryToProto(s metricdata.Summary) *metricspb.Summary {
+	out := &metricspb.Summary{}
+	for _, dp := range s.DataPoints {
+		pt := &metricspb.SummaryDataPoint{
+			Attributes:        attributesToProto(dp.Attributes.ToSlice()),
+			StartTimeUnixNano: timeToProto(dp.StartTime),
+			TimeUnixNano:      timeToProto(dp.Time),
+			Count:             dp.Count,
+			Sum:               dp.Sum,
+		}
+		for _, q := range dp.QuantileValues {
+			pt.QuantileValues = append(pt.QuantileValues, &metricspb.SummaryDataPoint_ValueAtQuantile{
+				Quantile: q.Quantile,
+				Value:    q.Value,
+			})
+		}
+		out.DataPoints = append(out.DataPoints, pt)
+	}
+	return out
+}
+
+func exemplarsToProto[N int64 | float64](exemplars []metricdata.Exemplar[N]) []*metricspb.Exemplar {
+	if len(exemplars) == 0 {
+		return nil
+	}
+	out := make([]*metricspb.Exemplar, 0, len(exemplars))
+	for _, e := range exemplars {
+		ex := &metricspb.Exemplar{
+			FilteredAttributes: attributesToProto(e.FilteredAttributes),
+			TimeUnixNano:       timeToProto(e.Time),
+			SpanId:             e.SpanID,
+			TraceId:            e.TraceID,
+		}
+		switch v := any(e.Value).(type) {
+		case int64:
+			ex.Value = &metricspb.Exemplar_AsInt{AsInt: v}
+		case float64:
+			ex.Value = &metricspb.Exemplar_AsDouble{AsDouble: v}
+		}
+		out = append(out, ex)
+	}
+	return out
+}
+
+func extremaToProto[N int64 | float64](e metricdata.Extrema[N]) *float64 {
+	v, ok := e.Value()
+	if !ok {
+		return nil
+	}
+	f := float64(v)
+	return &f
+}
+
+// resourceLogsToProto converts SDK log records into their OTLP protobuf representation,
+// grouping them by resource and instrumentation scope.
+func resourceLogsToProto(records []log.Record) []*logspb.ResourceLogs {
+	type scopeKey struct {
+		resource attribute.Distinct
+		scope    instrumentation.Scope
+	}
+	var out []*logspb.ResourceLogs
+	resourceLogs := map[attribute.Distinct]*logspb.ResourceLogs{}
+	scopeLogs := map[scopeKey]*logspb.ScopeLogs{}
+	for i := range records {
+		record := &records[i]
+		res := record.Resource()
+		rl, ok := resourceLogs[res.Equivalent()]
+		if !ok {
+			rl = &logspb.ResourceLogs{
+				Resource:  resourceToProto(&res),
+				SchemaUrl: res.SchemaURL(),
+			}
+			resourceLogs[res.Equivalent()] = rl
+			out = append(out, rl)
+		}
+		scope := record.InstrumentationScope()
+		key := scopeKey{resource: res.Equivalent(), scope: scope}
+		sl, ok := scopeLogs[key]
+		if !ok {
+			sl = &logspb.ScopeLogs{
+				Scope:     scopeToProto(scope),
+				SchemaUrl: scope.SchemaURL,
+			}
+			scopeLogs[key] = sl
+			rl.ScopeLogs = append(rl.ScopeLogs, sl)
+		}
+		sl.LogRecords = append(sl.LogRecords, logRecordToProto(record))
+	}
+	return out
+}
+
+func logRecordToProto(r *log.Record) *logspb.LogRecord {
+	out := &logspb.LogRecord{
+		TimeUnixNano:           timeToProto(r.Timestamp()),
+		ObservedTimeUnixNano:   timeToProto(r.ObservedTimestamp()),
+		SeverityNumber:         logspb.SeverityNumber(r.Severity()),
+		SeverityText:           r.SeverityText(),
+		Body:                   logValueToProto(r.Body()),
+		DroppedAttributesCount: uint32(r.DroppedAttributes()),
+		Flags:                  uint32(r.TraceFlags()),
+	}
+	r.WalkAttributes(func(kv otellog.KeyValue) bool {
+		out.Attributes = append(out.Attributes, &commonpb.KeyValue{
+			Key:   kv.Key,
+			Value: logValueToProto(kv.Value),
+		})
+		return true
+	})
+	if traceID := r.TraceID(); traceID.IsValid() {
+		out.TraceId = traceID[:]
+	}
+	if spanID := r.SpanID(); spanID.IsValid() {
+		out.SpanId = spanID[:]
+	}
+	return out
+}
+
+func logValueToProto(v otellog.Value) *commonpb.AnyValue {
+	switch v.Kind() {
+	case otellog.KindBool:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: v.AsBool()}}
+	case otellog.KindInt64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: v.AsInt64()}}
+	case otellog.KindFloat64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: v.AsFloat64()}}
+	case otellog.KindString:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v.AsString()}}
+	case otellog.KindBytes:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BytesValue{BytesValue: v.AsBytes()}}
+	case otellog.KindSlice:
+		var values []*commonpb.AnyValue
+		for _, item := range v.AsSlice() {
+			values = append(values, logValueToProto(item))
+		}
+		return arrayValueToProto(values)
+	case otellog.KindMap:
+		var values []*commonpb.KeyValue
+		for _, kv := range v.AsMap() {
+			values = append(values, &commonpb.KeyValue{Key: kv.Key, Value: logValueToProto(kv.Value)})
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: &commonpb.KeyValueList{Values: values}}}
+	default:
+		return nil
+	}
+}
+
+func temporalityToProto(t metricdata.Temporality) metricspb.AggregationTemporality {
+	switch t {
+	case metricdata.DeltaTemporality:
+		return metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA
+	case metricdata.CumulativeTemporality:
+		return metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
+	default:
+		return metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_UNSPECIFIED
+	}
+}
+
+func timeToProto(t time.Time) uint64 {
+	if t.IsZero() {
+		return 0
+	}
+	return uint64(t.UnixNano())
+}
+
+func resourceToProto(r *resource.Resource) *resourcepb.Resource {
+	if r == nil {
+		return &resourcepb.Resource{}
+	}
+	return &resourcepb.Resource{Attributes: attributesToProto(r.Attributes())}
+}
+
+func scopeToProto(s instrumentation.Scope) *commonpb.InstrumentationScope {
+	return &commonpb.InstrumentationScope{
+		Name:    s.Name,
+		Version: s.Version,
+	}
+}
+
+func attributesToProto(attrs []attribute.KeyValue) []*commonpb.KeyValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+	out := make([]*commonpb.KeyValue, 0, len(attrs))
+	for _, kv := range attrs {
+		out = append(out, &commonpb.KeyValue{
+			Key:   string(kv.Key),
+			Value: attributeValueToProto(kv.Value),
+		})
+	}
+	return out
+}
+
+func attributeValueToProto(v attribute.Value) *commonpb.AnyValue {
+	switch v.Type() {
+	case attribute.BOOL:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: v.AsBool()}}
+	case attribute.INT64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: v.AsInt64()}}
+	case attribute.FLOAT64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: v.AsFloat64()}}
+	case attribute.STRING:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v.AsString()}}
+	case attribute.BOOLSLICE:
+		var values []*commonpb.AnyValue
+		for _, b := range v.AsBoolSlice() {
+			values = append(values, &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: b}})
+		}
+		return arrayValueToProto(values)
+	case attribute.INT64SLICE:
+		var values []*commonpb.AnyValue
+		for _, i := range v.AsInt64Slice() {
+			values = append(values, &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: i}})
+		}
+		return arrayValueToProto(values)
+	case attribute.FLOAT64SLICE:
+		var values []*commonpb.AnyValue
+		for _, f := range v.AsFloat64Slice() {
+			values = append(values, &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: f}})
+		}
+		return arrayValueToProto(values)
+	case attribute.STRINGSLICE:
+		var values []*commonpb.AnyValue
+		for _, s := range v.AsStringSlice() {
+			values = append(values, &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: s}})
+		}
+		return arrayValueToProto(values)
+	default:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v.Emit()}}
+	}
+}
+
+func arrayValueToProto(values []*commonpb.AnyValue) *commonpb.AnyValue {
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{Values: values}}}
+}
@@ -0,0 +1,158 @@
+package otelconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/honeycombio/otel-config-go/otelconfig/pipelines"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// WithReloadSignals reloads the configuration whenever the process receives one of the
+// signals, typically syscall.SIGHUP. See SDK.Reload for what can be changed.
+func WithReloadSignals(signals ...os.Signal) Option {
+	return func(c *Config) {
+		c.reloadSignals = append(c.reloadSignals, signals...)
+	}
+}
+
+// WithConfigFileWatch checks the config file for changes every interval, and reloads the
+// configuration when it has changed. See SDK.Reload for what can be changed.
+func WithConfigFileWatch(interval time.Duration) Option {
+	return func(c *Config) {
+		c.configFileWatchInterval = interval
+	}
+}
+
+// Reload resolves the configuration again from the options the SDK was built with, the
+// config file and the environment, and applies it to the running pipelines: the sampler,
+// the log level of the default logger, and the endpoints, headers and other settings of
+// the OTLP exporters. Telemetry that is buffered is kept and sent with the new settings.
+// Everything else, such as which signals and exporters are enabled, only changes on
+// restart, and Config keeps describing the configuration the SDK was built with.
+func (s *SDK) Reload() error {
+	c, err := newConfig(s.opts...)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	if s.sampler != nil {
+		s.sampler.set(c.Sampler)
+	}
+	if s.tracesReloader != nil {
+		errs = append(errs, reloadPipeline(c, s, tracesPipelineConfig, s.tracesReloader.Reload))
+	}
+	if s.metricsReloader != nil {
+		errs = append(errs, reloadPipeline(c, s, metricsPipelineConfig, s.metricsReloader.Reload))
+	}
+	if s.logsReloader != nil {
+		errs = append(errs, reloadPipeline(c, s, logsPipelineConfig, s.logsReloader.Reload))
+	}
+	return errors.Join(errs...)
+}
+
+func reloadPipeline(c *Config, s *SDK,
+	pipelineConfig func(*Config, *SDK) (pipelines.PipelineConfig, error),
+	reload func(pipelines.PipelineConfig) error,
+) error {
+	pc, err := pipelineConfig(c, s)
+	if err != nil {
+		return err
+	}
+	return reload(pc)
+}
+
+// watchForReload reloads the configuration when one of the reload signals is received or
+// the config file changes, until stopWatching is called.
+func (s *SDK) watchForReload() {
+	c := s.Config
+	if len(c.reloadSignals) == 0 && (c.configFileWatchInterval <= 0 || c.ConfigFile == "") {
+		return
+	}
+
+	signals := make(chan os.Signal, 1)
+	if len(c.reloadSignals) > 0 {
+		signal.Notify(signals, c.reloadSignals...)
+	}
+	var ticker *time.Ticker
+	var tick <-chan time.Time
+	var lastModified os.FileInfo
+	if c.configFileWatchInterval > 0 && c.ConfigFile != "" {
+		ticker = time.NewTicker(c.configFileWatchInterval)
+		tick = ticker.C
+		lastModified, _ = os.Stat(c.ConfigFile)
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	// Shutdown may be called more than once
+	var stopOnce sync.Once
+	s.stopWatching = func() {
+		stopOnce.Do(func() {
+			close(stop)
+			<-done
+		})
+	}
+	go func() {
+		defer close(done)
+		defer signal.Stop(signals)
+		if ticker != nil {
+			defer ticker.Stop()
+		}
+		for {
+			select {
+			case <-stop:
+				return
+			case <-signals:
+			case <-tick:
+				info, err := os.Stat(c.ConfigFile)
+				if err != nil || (lastModified != nil &&
+					info.ModTime().Equal(lastModified.ModTime()) && info.Size() == lastModified.Size()) {
+					continue
+				}
+				lastModified = info
+			}
+			if err := s.Reload(); err != nil {
+				handler := c.errorHandler
+				if handler == nil {
+					handler = otel.GetErrorHandler()
+				}
+				handler.Handle(fmt.Errorf("failed to reload configuration: %w", err))
+				continue
+			}
+			c.Logger.Debugf("configuration reloaded")
+		}
+	}()
+}
+
+// reloadableSampler delegates to a sampler that can be replaced while spans are being
+// sampled.
+type reloadableSampler struct {
+	sampler atomic.Value
+}
+
+var _ trace.Sampler = (*reloadableSampler)(nil)
+
+type samplerHolder struct {
+	trace.Sampler
+}
+
+func (s *reloadableSampler) set(sampler trace.Sampler) {
+	s.sampler.Store(samplerHolder{sampler})
+}
+
+func (s *reloadableSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	return s.sampler.Load().(samplerHolder).ShouldSample(p)
+}
+
+func (s *reloadableSampler) Description() string {
+	return s.sampler.Load().(samplerHolder).Description()
+}
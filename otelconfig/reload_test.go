@@ -0,0 +1,204 @@
+package otelconfig
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+func sampled(tp *trace.TracerProvider) bool {
+	_, span := tp.Tracer("otelconfig-tests").Start(context.Background(), "test-span")
+	defer span.End()
+	return span.SpanContext().IsSampled()
+}
+
+func TestReloadSwapsExporterEndpoint(t *testing.T) {
+	var first, second atomic.Int32
+	newCollector := func(received *atomic.Int32) *httptest.Server {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received.Add(1)
+		}))
+		t.Cleanup(ts.Close)
+		return ts
+	}
+	firstCollector := newCollector(&first)
+	secondCollector := newCollector(&second)
+
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", firstCollector.URL)
+	sdk, err := NewSDK(
+		WithLogger(&testLogger{}),
+		WithTracesExporterProtocol("http/protobuf"),
+		// make sure spans are only exported when flushed
+		WithBatchSpanProcessorScheduleDelay(time.Hour),
+		WithMetricsEnabled(false),
+		WithLogsEnabled(false),
+	)
+	require.NoError(t, err)
+	defer sdk.Shutdown(context.Background())
+	tracer := sdk.TracerProvider.Tracer("otelconfig-tests")
+
+	_, span := tracer.Start(context.Background(), "before-reload")
+	span.End()
+	require.NoError(t, sdk.ForceFlush(context.Background()))
+	assert.Equal(t, int32(1), first.Load())
+
+	// a span that is buffered while reloading is sent to the new endpoint
+	_, span = tracer.Start(context.Background(), "during-reload")
+	span.End()
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", secondCollector.URL)
+	require.NoError(t, sdk.Reload())
+	require.NoError(t, sdk.ForceFlush(context.Background()))
+
+	assert.Equal(t, int32(1), first.Load())
+	assert.Equal(t, int32(1), second.Load())
+}
+
+func TestReloadDoesNotWaitForInFlightExports(t *testing.T) {
+	arrived, release := make(chan struct{}), make(chan struct{})
+	stuck := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		<-release
+	}))
+	defer stuck.Close()
+	defer close(release)
+	var received atomic.Int32
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+	}))
+	defer healthy.Close()
+
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", stuck.URL)
+	sdk, err := NewSDK(
+		WithLogger(&testLogger{}),
+		WithTracesExporterProtocol("http/protobuf"),
+		WithBatchSpanProcessorScheduleDelay(time.Hour),
+		WithMetricsEnabled(false),
+		WithLogsEnabled(false),
+	)
+	require.NoError(t, err)
+	tracer := sdk.TracerProvider.Tracer("otelconfig-tests")
+
+	_, span := tracer.Start(context.Background(), "stuck")
+	span.End()
+	flushed := make(chan error, 1)
+	go func() {
+		flushed <- sdk.ForceFlush(context.Background())
+	}()
+	<-arrived
+
+	// the reload returns while the export to the old endpoint is still in flight
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", healthy.URL)
+	reloaded := make(chan error, 1)
+	go func() {
+		reloaded <- sdk.Reload()
+	}()
+	select {
+	case err := <-reloaded:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("reload waited for the in-flight export")
+	}
+
+	release <- struct{}{}
+	require.NoError(t, <-flushed)
+	_, span = tracer.Start(context.Background(), "after-reload")
+	span.End()
+	require.NoError(t, sdk.ForceFlush(context.Background()))
+	assert.Equal(t, int32(1), received.Load())
+	require.NoError(t, sdk.Shutdown(context.Background()))
+}
+
+func TestReloadOnSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signals can't be sent to the process on windows")
+	}
+	stopper := dummyGRPCListener()
+	defer stopper()
+
+	t.Setenv("OTEL_TRACES_SAMPLER", "always_off")
+	sdk, err := NewSDK(
+		WithLogger(&testLogger{}),
+		withTestExporters(),
+		WithReloadSignals(syscall.SIGHUP),
+		WithMetricsEnabled(false),
+		WithLogsEnabled(false),
+	)
+	require.NoError(t, err)
+	defer sdk.Shutdown(context.Background())
+	require.False(t, sampled(sdk.TracerProvider))
+
+	t.Setenv("OTEL_TRACES_SAMPLER", "always_on")
+	process, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	require.NoError(t, process.Signal(syscall.SIGHUP))
+
+	assert.Eventually(t, func() bool {
+		return sampled(sdk.TracerProvider)
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestReloadOnConfigFileChange(t *testing.T) {
+	stopper := dummyGRPCListener()
+	defer stopper()
+
+	const configFile = `
+file_format: "0.3"
+tracer_provider:
+  processors:
+    - batch:
+        exporter:
+          otlp:
+            endpoint: localhost:4317
+            insecure: true
+  sampler:
+    trace_id_ratio_based:
+      ratio: %s
+`
+	path := writeConfigFile(t, fmt.Sprintf(configFile, "0.0"))
+	sdk, err := NewSDK(
+		WithLogger(&testLogger{}),
+		WithConfigFile(path),
+		WithConfigFileWatch(10*time.Millisecond),
+	)
+	require.NoError(t, err)
+	defer sdk.Shutdown(context.Background())
+	require.False(t, sampled(sdk.TracerProvider))
+
+	require.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf(configFile, "1")), 0o644))
+
+	assert.Eventually(t, func() bool {
+		return sampled(sdk.TracerProvider)
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "TraceIDRatioBased{0}", sdk.Config.Sampler.Description())
+}
+
+func TestShutdownTwiceWhileWatching(t *testing.T) {
+	stopper := dummyGRPCListener()
+	defer stopper()
+
+	sdk, err := NewSDK(
+		WithLogger(&testLogger{}),
+		withTestExporters(),
+		WithReloadSignals(syscall.SIGHUP),
+		WithMetricsEnabled(false),
+		WithLogsEnabled(false),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, sdk.Shutdown(context.Background()))
+	assert.NotPanics(t, func() {
+		_ = sdk.Shutdown(context.Background())
+	})
+}
@@ -0,0 +1,161 @@
+package otelconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/honeycombio/otel-config-go/otelconfig/pipelines"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/log/global"
+	"go.opentelemetry.io/otel/propagation"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// SDK holds the OpenTelemetry providers built from a Config. Building an SDK doesn't change
+// any global state, so several of them can be used side by side; call RegisterGlobals to
+// make one of them the global default.
+type SDK struct {
+	// TracerProvider is nil if tracing is disabled.
+	TracerProvider *trace.TracerProvider
+	// MeterProvider is nil if metrics are disabled.
+	MeterProvider *metric.MeterProvider
+	// LoggerProvider is nil if logs are disabled.
+	LoggerProvider *sdklog.LoggerProvider
+	// Propagator combines the configured propagators. It is nil if tracing is disabled.
+	Propagator propagation.TextMapPropagator
+	// Config is the configuration after options and environment variables have been applied.
+	Config *Config
+
+	// fileWriter is shared by the file exporters, and closed once they have shut down.
+	fileWriter io.WriteCloser
+
+	// opts, sampler and the reloaders are used by Reload to apply a new configuration.
+	opts            []Option
+	sampler         *reloadableSampler
+	tracesReloader  *pipelines.Reloader
+	metricsReloader *pipelines.Reloader
+	logsReloader    *pipelines.Reloader
+	// stopWatching stops reloading on signals and config file changes, if it was enabled.
+	stopWatching func()
+}
+
+// provider is implemented by the SDK's TracerProvider, MeterProvider and LoggerProvider.
+type provider interface {
+	ForceFlush(ctx context.Context) error
+	Shutdown(ctx context.Context) error
+}
+
+// NewSDK builds the traces, metrics and logs pipelines described by the options, without
+// registering anything globally.
+// If one of the pipelines fails to start, the SDK is returned along with the error so that
+// the pipelines that did start can still be shut down.
+func NewSDK(opts ...Option) (*SDK, error) {
+	c, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.LogLevel == "debug" {
+		c.Logger.Debugf("debug logging enabled")
+		c.Logger.Debugf("configuration")
+		// secrets such as API keys in the headers are masked
+		c.Logger.Debugf("%s", c)
+	}
+
+	// Give a vendor a chance to validate the configuration
+	if ValidateConfig != nil {
+		if err := ValidateConfig(c); err != nil {
+			return nil, err
+		}
+	}
+
+	sdk := &SDK{Config: c, opts: opts}
+	for _, setup := range []setupFunc{setupFileWriter, setupTracing, setupMetrics, setupLogs} {
+		if err := setup(c, sdk); err != nil {
+			return sdk, fmt.Errorf("setup error: %w", err)
+		}
+	}
+	sdk.watchForReload()
+	return sdk, nil
+}
+
+// RegisterGlobals makes the SDK's providers and propagator the global ones returned by
+// the otel and log/global packages. Disabled signals leave the global provider unchanged.
+func (s *SDK) RegisterGlobals() {
+	if s.Propagator != nil {
+		otel.SetTextMapPropagator(s.Propagator)
+	}
+	if s.TracerProvider != nil {
+		otel.SetTracerProvider(s.TracerProvider)
+	}
+	if s.MeterProvider != nil {
+		otel.SetMeterProvider(s.MeterProvider)
+	}
+	if s.LoggerProvider != nil {
+		global.SetLoggerProvider(s.LoggerProvider)
+	}
+}
+
+// Shutdown calls the config shutdown functions, then shuts down the traces, metrics and logs
+// pipelines. Every function is called even if an earlier one fails, and the failures are
+// returned together as a single error. Pipelines stop waiting for their final export once
+// ctx is done.
+func (s *SDK) Shutdown(ctx context.Context) error {
+	if s.stopWatching != nil {
+		s.stopWatching()
+	}
+
+	var errs []error
+	// call config shutdown functions first
+	for _, shutdown := range s.Config.ShutdownFunctions {
+		if err := shutdown(s.Config); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop exporter while calling config shutdown: %w", err))
+		}
+	}
+
+	for _, p := range s.providers() {
+		if err := p.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop exporter: %w", err))
+		}
+	}
+
+	if s.fileWriter != nil {
+		if err := s.fileWriter.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close exporter file: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ForceFlush exports any spans, metrics and log records that are buffered by the pipelines,
+// without shutting them down. Every pipeline is flushed even if an earlier one fails, and the
+// failures are returned together as a single error.
+func (s *SDK) ForceFlush(ctx context.Context) error {
+	var errs []error
+	for _, p := range s.providers() {
+		if err := p.ForceFlush(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to flush exporter: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// providers returns the providers of the enabled pipelines, in the order they were set up.
+func (s *SDK) providers() []provider {
+	var providers []provider
+	if s.TracerProvider != nil {
+		providers = append(providers, s.TracerProvider)
+	}
+	if s.MeterProvider != nil {
+		providers = append(providers, s.MeterProvider)
+	}
+	if s.LoggerProvider != nil {
+		providers = append(providers, s.LoggerProvider)
+	}
+	return providers
+}
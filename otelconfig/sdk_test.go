@@ -0,0 +1,87 @@
+package otelconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+func serviceName(t *testing.T, recorder *tracetest.SpanRecorder) string {
+	t.Helper()
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	attrs := attribute.NewSet(spans[0].Resource().Attributes()...)
+	v, ok := attrs.Value(semconv.ServiceNameKey)
+	require.True(t, ok)
+	return v.AsString()
+}
+
+func TestNewSDKsCanBeUsedSideBySide(t *testing.T) {
+	stopper := dummyGRPCListener()
+	defer stopper()
+
+	globalTracerProvider := otel.GetTracerProvider()
+	globalMeterProvider := otel.GetMeterProvider()
+
+	firstRecorder := tracetest.NewSpanRecorder()
+	first, err := NewSDK(
+		WithLogsEnabled(true),
+		WithLogger(&testLogger{}),
+		WithServiceName("first-service"),
+		WithSpanProcessor(firstRecorder),
+		withTestExporters(),
+	)
+	require.NoError(t, err)
+	defer func() { _ = first.Shutdown(context.Background()) }()
+
+	secondRecorder := tracetest.NewSpanRecorder()
+	second, err := NewSDK(
+		WithLogger(&testLogger{}),
+		WithServiceName("second-service"),
+		WithSpanProcessor(secondRecorder),
+		withTestExporters(),
+	)
+	require.NoError(t, err)
+	defer func() { _ = second.Shutdown(context.Background()) }()
+
+	assert.Same(t, globalTracerProvider, otel.GetTracerProvider())
+	assert.Same(t, globalMeterProvider, otel.GetMeterProvider())
+	assert.NotNil(t, first.MeterProvider)
+	assert.NotNil(t, first.LoggerProvider)
+	assert.NotNil(t, first.Propagator)
+	assert.Equal(t, "first-service", first.Config.ServiceName)
+
+	_, span := first.TracerProvider.Tracer("test").Start(context.Background(), "first-span")
+	span.End()
+	_, span = second.TracerProvider.Tracer("test").Start(context.Background(), "second-span")
+	span.End()
+
+	assert.Equal(t, "first-service", serviceName(t, firstRecorder))
+	assert.Equal(t, "second-service", serviceName(t, secondRecorder))
+}
+
+func TestSDKRegisterGlobals(t *testing.T) {
+	stopper := dummyGRPCListener()
+	defer stopper()
+
+	sdk, err := NewSDK(
+		WithLogger(&testLogger{}),
+		WithMetricsEnabled(false),
+		withTestExporters(),
+	)
+	require.NoError(t, err)
+	defer func() { _ = sdk.Shutdown(context.Background()) }()
+	assert.Nil(t, sdk.MeterProvider)
+
+	globalMeterProvider := otel.GetMeterProvider()
+	sdk.RegisterGlobals()
+	assert.Same(t, sdk.TracerProvider, otel.GetTracerProvider())
+	assert.Same(t, globalMeterProvider, otel.GetMeterProvider(), "disabled signals are left alone")
+}
@@ -0,0 +1,174 @@
+package otelconfig
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+)
+
+// slogInstrumentationName is the instrumentation scope used for records sent through the slog handler.
+const slogInstrumentationName = "github.com/honeycombio/otel-config-go/otelconfig"
+
+// NewSlogHandler returns a slog.Handler that sends records through the global LoggerProvider.
+// Records are emitted with the context passed to the slog call, so they carry the trace and span
+// IDs of the active span, and the Resource of the configured logs pipeline.
+func NewSlogHandler() slog.Handler {
+	return NewSlogHandlerWithProvider(global.GetLoggerProvider())
+}
+
+// NewSlogHandlerWithProvider returns a slog.Handler that sends records through provider,
+// such as the LoggerProvider of an SDK that isn't registered globally.
+func NewSlogHandlerWithProvider(provider otellog.LoggerProvider) slog.Handler {
+	return &slogHandler{
+		logger: provider.Logger(slogInstrumentationName, otellog.WithInstrumentationVersion(version)),
+		group:  &slogGroup{},
+	}
+}
+
+// slogHandler is a slog.Handler that converts slog records into OpenTelemetry log records.
+type slogHandler struct {
+	logger otellog.Logger
+	group  *slogGroup
+}
+
+var _ slog.Handler = (*slogHandler)(nil)
+
+// slogGroup holds the attributes added to a handler under a group name;
+// the root group has an empty name and no parent.
+type slogGroup struct {
+	name   string
+	attrs  []otellog.KeyValue
+	parent *slogGroup
+}
+
+// Enabled always returns true; filtering is left to the LoggerProvider's processors.
+func (h *slogHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+// Handle converts the slog record and emits it with the given context.
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	var record otellog.Record
+	record.SetTimestamp(r.Time)
+	record.SetBody(otellog.StringValue(r.Message))
+	record.SetSeverity(slogSeverity(r.Level))
+	record.SetSeverityText(r.Level.String())
+
+	kvs := make([]otellog.KeyValue, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		kvs = appendSlogAttr(kvs, a)
+		return true
+	})
+	for g := h.group; g != nil; g = g.parent {
+		kvs = append(g.attrs[:len(g.attrs):len(g.attrs)], kvs...)
+		if g.name != "" && len(kvs) > 0 {
+			kvs = []otellog.KeyValue{otellog.Map(g.name, kvs...)}
+		}
+	}
+	record.AddAttributes(kvs...)
+
+	h.logger.Emit(ctx, record)
+	return nil
+}
+
+// slogSeverity converts a slog level into a severity number. slog levels are 4 apart,
+// starting at -4 for debug, which lines up with the first severity number of each
+// OpenTelemetry severity range; levels beyond those ranges get the lowest or highest one.
+func slogSeverity(level slog.Level) otellog.Severity {
+	severity := level + 9
+	switch {
+	case severity < slog.Level(otellog.SeverityTrace1):
+		return otellog.SeverityTrace1
+	case severity > slog.Level(otellog.SeverityFatal4):
+		return otellog.SeverityFatal4
+	}
+	return otellog.Severity(severity)
+}
+
+// WithAttrs returns a handler that adds attrs to the current group of every record.
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	g := *h.group
+	g.attrs = g.attrs[:len(g.attrs):len(g.attrs)]
+	for _, a := range attrs {
+		g.attrs = appendSlogAttr(g.attrs, a)
+	}
+	return &slogHandler{logger: h.logger, group: &g}
+}
+
+// WithGroup returns a handler that nests all subsequent attributes under name.
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{logger: h.logger, group: &slogGroup{name: name, parent: h.group}}
+}
+
+// appendSlogAttr converts a slog.Attr and appends it to kvs, following the slog.Handler rules
+// for empty attributes and inlined groups.
+func appendSlogAttr(kvs []otellog.KeyValue, a slog.Attr) []otellog.KeyValue {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return kvs
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		group := a.Value.Group()
+		if len(group) == 0 {
+			return kvs
+		}
+		if a.Key == "" {
+			for _, ga := range group {
+				kvs = appendSlogAttr(kvs, ga)
+			}
+			return kvs
+		}
+	}
+	return append(kvs, otellog.KeyValue{Key: a.Key, Value: convertSlogValue(a.Value)})
+}
+
+// convertSlogValue converts a resolved slog.Value into the closest otellog.Value.
+func convertSlogValue(v slog.Value) otellog.Value {
+	switch v.Kind() {
+	case slog.KindBool:
+		return otellog.BoolValue(v.Bool())
+	case slog.KindDuration:
+		return otellog.Int64Value(v.Duration().Nanoseconds())
+	case slog.KindFloat64:
+		return otellog.Float64Value(v.Float64())
+	case slog.KindInt64:
+		return otellog.Int64Value(v.Int64())
+	case slog.KindString:
+		return otellog.StringValue(v.String())
+	case slog.KindTime:
+		return otellog.Int64Value(v.Time().UnixNano())
+	case slog.KindUint64:
+		u := v.Uint64()
+		if u > math.MaxInt64 {
+			return otellog.StringValue(fmt.Sprint(u))
+		}
+		return otellog.Int64Value(int64(u))
+	case slog.KindGroup:
+		var kvs []otellog.KeyValue
+		for _, a := range v.Group() {
+			kvs = appendSlogAttr(kvs, a)
+		}
+		return otellog.MapValue(kvs...)
+	default:
+		switch val := v.Any().(type) {
+		case []byte:
+			return otellog.BytesValue(val)
+		case error:
+			return otellog.StringValue(val.Error())
+		case fmt.Stringer:
+			return otellog.StringValue(val.String())
+		default:
+			return otellog.StringValue(fmt.Sprintf("%+v", val))
+		}
+	}
+}
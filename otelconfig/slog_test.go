@@ -0,0 +1,164 @@
+package otelconfig
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/attribute"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// recordingLogExporter keeps every exported record in memory.
+type recordingLogExporter struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (e *recordingLogExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.records = append(e.records, records...)
+	return nil
+}
+
+func (e *recordingLogExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func (e *recordingLogExporter) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+func recordAttributes(r sdklog.Record) map[string]otellog.Value {
+	attrs := map[string]otellog.Value{}
+	r.WalkAttributes(func(kv otellog.KeyValue) bool {
+		attrs[kv.Key] = kv.Value
+		return true
+	})
+	return attrs
+}
+
+func TestSlogHandlerCorrelatesTraceAndResource(t *testing.T) {
+	res := resource.NewSchemaless(attribute.String("service.name", "slog-test"))
+	exporter := &recordingLogExporter{}
+	provider := sdklog.NewLoggerProvider(
+		sdklog.WithResource(res),
+		sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter)),
+	)
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+
+	ctx, span := trace.NewTracerProvider().Tracer("slog-test").Start(context.Background(), "test-span")
+	defer span.End()
+
+	logger := slog.New(NewSlogHandlerWithProvider(provider))
+	logger.WarnContext(ctx, "something happened", "count", 3)
+
+	require.Len(t, exporter.records, 1)
+	record := exporter.records[0]
+	assert.Equal(t, "something happened", record.Body().AsString())
+	assert.Equal(t, otellog.SeverityWarn, record.Severity())
+	assert.Equal(t, "WARN", record.SeverityText())
+	assert.Equal(t, span.SpanContext().TraceID(), record.TraceID())
+	assert.Equal(t, span.SpanContext().SpanID(), record.SpanID())
+	recordResource := record.Resource()
+	assert.Equal(t, res.Attributes(), recordResource.Attributes())
+	assert.Equal(t, int64(3), recordAttributes(record)["count"].AsInt64())
+}
+
+func TestSlogHandlerAttributesAndGroups(t *testing.T) {
+	exporter := &recordingLogExporter{}
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter)))
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+
+	logger := slog.New(NewSlogHandlerWithProvider(provider)).
+		With("handler.attr", "root").
+		WithGroup("request").
+		With("method", "GET")
+	logger.Info("handled",
+		slog.Int("status", 200),
+		slog.Group("", slog.Bool("inlined", true)),
+		slog.Group("empty"),
+		slog.Any("err", errors.New("boom")),
+	)
+
+	require.Len(t, exporter.records, 1)
+	attrs := recordAttributes(exporter.records[0])
+	assert.Equal(t, "root", attrs["handler.attr"].AsString())
+	require.Contains(t, attrs, "request")
+	assert.Equal(t, []otellog.KeyValue{
+		otellog.String("method", "GET"),
+		otellog.Int64("status", 200),
+		otellog.Bool("inlined", true),
+		otellog.String("err", "boom"),
+	}, attrs["request"].AsMap())
+}
+
+func TestSlogHandlerClampsSeverity(t *testing.T) {
+	exporter := &recordingLogExporter{}
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter)))
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+
+	logger := slog.New(NewSlogHandlerWithProvider(provider))
+	for _, level := range []slog.Level{slog.LevelDebug - 20, slog.LevelDebug, slog.LevelError, slog.LevelError + 20} {
+		logger.Log(context.Background(), level, "message")
+	}
+
+	require.Len(t, exporter.records, 4)
+	var severities []otellog.Severity
+	for _, record := range exporter.records {
+		severities = append(severities, record.Severity())
+	}
+	assert.Equal(t, []otellog.Severity{
+		otellog.SeverityTrace1,
+		otellog.SeverityDebug,
+		otellog.SeverityError,
+		otellog.SeverityFatal4,
+	}, severities)
+}
+
+func TestWithSlogDefault(t *testing.T) {
+	stopper := dummyGRPCListener()
+	defer stopper()
+
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+
+	shutdown, err := ConfigureOpenTelemetry(
+		WithLogsEnabled(true),
+		WithServiceName("test-service"),
+		WithSlogDefault(true),
+		withTestExporters(),
+	)
+	require.NoError(t, err)
+	defer shutdown()
+
+	assert.IsType(t, &slogHandler{}, slog.Default().Handler())
+}
+
+func TestWithSlogDefaultIgnoredWhenLogsDisabled(t *testing.T) {
+	stopper := dummyGRPCListener()
+	defer stopper()
+
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+
+	shutdown, err := ConfigureOpenTelemetry(
+		WithServiceName("test-service"),
+		WithSlogDefault(true),
+		WithLogsEnabled(false),
+		withTestExporters(),
+	)
+	require.NoError(t, err)
+	defer shutdown()
+
+	assert.Same(t, previous.Handler(), slog.Default().Handler())
+}